@@ -0,0 +1,520 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"wp/internal/wp"
+)
+
+// globalOptions はすべてのコマンドで使えるフラグの値です
+type globalOptions struct {
+	config    string
+	profile   string
+	articles  string
+	images    string
+	cache     string
+	cacheTTL  time.Duration
+	record    string
+	replay    string
+	verbose   bool
+	vverbose  bool
+	logFormat string
+	output    string
+}
+
+func newGlobalOptions() *globalOptions {
+	return &globalOptions{cacheTTL: time.Hour, logFormat: "text", output: "text"}
+}
+
+// register は fs に共通のフラグを定義します。コマンドの前で指定された値を残すため、既定値には現在の値を使います。
+func (g *globalOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&g.config, "config", g.config, text{
+		"プロファイルを定義した設定ファイル（省略時はカレントディレクトリから親へ wp.json を探す）",
+		"config file defining profiles (default: wp.json searched from the current directory upwards)"}.String())
+	fs.StringVar(&g.profile, "profile", g.profile, text{
+		"投稿先のプロファイル（省略時は設定ファイルの default）",
+		"profile to publish to (default: the config file's default)"}.String())
+	fs.StringVar(&g.articles, "articles", g.articles, text{
+		"記事の置き場所（省略時は WP_ARTICLES_DIR、設定ファイルの articles_dir、internal/articles の順）",
+		"articles directory (default: WP_ARTICLES_DIR, articles_dir in the config file, then internal/articles)"}.String())
+	fs.StringVar(&g.images, "images", g.images, text{
+		"画像の置き場所（省略時は WP_IMAGES_DIR、設定ファイルの images_dir、internal/images の順）",
+		"images directory (default: WP_IMAGES_DIR, images_dir in the config file, then internal/images)"}.String())
+	fs.StringVar(&g.cache, "cache", g.cache, text{
+		"ターム・メディアのキャッシュファイル (例: .wp-cache.json)",
+		"cache file for terms and media (e.g. .wp-cache.json)"}.String())
+	fs.DurationVar(&g.cacheTTL, "cache-ttl", g.cacheTTL, text{
+		"キャッシュファイルの有効期間",
+		"how long the cache file stays valid"}.String())
+	fs.StringVar(&g.record, "record", g.record, text{
+		"送受信したリクエストとレスポンスをディレクトリに記録する（認証情報は伏せる）",
+		"record requests and responses to a directory (credentials are redacted)"}.String())
+	fs.StringVar(&g.replay, "replay", g.replay, text{
+		"-record で記録したレスポンスを再生し、WordPress に接続せずに実行する",
+		"replay responses recorded with -record instead of connecting to WordPress"}.String())
+	fs.BoolVar(&g.verbose, "v", g.verbose, text{
+		"公開処理の各段階と HTTP リクエストの結果（メソッド、URL、ステータス、所要時間）を標準エラー出力に表示する",
+		"log publish stages and HTTP requests (method, URL, status, duration) to stderr"}.String())
+	fs.BoolVar(&g.vverbose, "vv", g.vverbose, text{
+		"-v に加えて HTTP のヘッダーと本文（先頭 2KB、認証情報は伏せる）も表示する",
+		"like -v, plus HTTP headers and bodies (first 2KB, credentials redacted)"}.String())
+	fs.StringVar(&g.logFormat, "log-format", g.logFormat, text{
+		"-v・-vv のログ形式（text または json）",
+		"log format for -v and -vv (text or json)"}.String())
+	fs.StringVar(&g.output, "output", g.output, text{
+		"出力形式（text または json）。json では結果を JSON で標準出力に、メッセージを標準エラー出力に出力する",
+		"output format (text or json); json writes the result to stdout and messages to stderr"}.String())
+}
+
+// validate は副作用のある処理の前にフラグの値を確かめます
+func (g *globalOptions) validate() error {
+	if g.output != "text" && g.output != "json" {
+		return usageError("-output には text または json を指定してください: %s", g.output)
+	}
+	if g.logFormat != "text" && g.logFormat != "json" {
+		return usageError("不正なログ形式: %s（text または json を指定してください）", g.logFormat)
+	}
+	if g.record != "" && g.replay != "" {
+		return usageError("-record と -replay は同時に指定できません")
+	}
+	return nil
+}
+
+// options はコマンドごとのフラグの値です
+type options struct {
+	force    bool
+	ours     bool
+	theirs   bool
+	markdown bool
+	json     bool
+	to       int
+	local    bool
+	status   string
+	prune    bool
+	from     string
+	title    string
+	slug     string
+	template string
+	prose    bool
+}
+
+func (o *options) publish() publishOptions {
+	opts := publishOptions{force: o.force}
+	switch {
+	case o.ours:
+		opts.resolution = "ours"
+	case o.theirs:
+		opts.resolution = "theirs"
+	}
+	return opts
+}
+
+// session はコマンドの実行に使う設定とクライアントです
+type session struct {
+	config  *wp.Config
+	profile wp.Profile
+	// workspace は記事と画像の置き場所、articlesDir は記事の置き場所のディレクトリです
+	workspace   *wp.Workspace
+	articlesDir string
+	// client は WordPress に接続するコマンドの場合だけ作成します
+	client *wp.Client
+	opts   *options
+}
+
+// コマンドの実行前に必要な準備
+const (
+	// needNothing は設定を読み込まずに実行します
+	needNothing = iota
+	// needWorkspace は設定ファイルから記事の置き場所を決めます。.env はなくてもかまいません。
+	needWorkspace
+	// needProfile は設定ファイルと .env からプロファイルを決めます
+	needProfile
+	// needClient はさらに認証情報を読み取ってクライアントを作成します
+	needClient
+)
+
+// command はサブコマンドです
+type command struct {
+	name string
+	// args は使い方に表示する引数です
+	args    text
+	summary text
+	// minArgs・maxArgs は引数の数です。maxArgs が -1 の場合は制限しません。
+	minArgs, maxArgs int
+	// articles は引数が記事名の場合に true です。実行前にファイルがあるかを確かめ、補完で記事名を候補にします。
+	articles bool
+	need     int
+	hidden   bool
+	// flags はコマンドのフラグを定義します
+	flags func(fs *flag.FlagSet, o *options)
+	// validate は設定を読み込む前にフラグの値を確かめます
+	validate func(o *options) error
+	run      func(s *session, args []string) error
+}
+
+var commands []*command
+
+func init() {
+	articleArgs := text{"<記事>...", "<article>..."}
+	commands = []*command{
+		{
+			name: "new", args: text{"[<ディレクトリ>|<記事>]", "[<directory>|<article>]"}, minArgs: 0, maxArgs: 1, need: needWorkspace,
+			summary: text{"テンプレートから記事ファイルを作成する", "create an article file from a template"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.StringVar(&o.title, "title", "", text{"記事のタイトル", "title of the article"}.String())
+				fs.StringVar(&o.slug, "slug", "", text{
+					"記事のスラッグ（省略時はタイトルから提案する）",
+					"slug of the article (default: suggested from the title)"}.String())
+				fs.StringVar(&o.template, "template", "", text{
+					"テンプレートにするファイル（省略時は記事のディレクトリから親へ _template.md を探す）",
+					"template file (default: _template.md searched from the article's directory upwards)"}.String())
+			},
+			run: func(s *session, args []string) error {
+				var arg string
+				if len(args) > 0 {
+					arg = args[0]
+				}
+				result, err := newArticle(s.workspace, s.articlesDir, arg, s.opts)
+				if err != nil {
+					report.Results = append(report.Results, failedResult(arg, "new", err))
+					return err
+				}
+				report.Results = append(report.Results, result)
+				return nil
+			},
+		},
+		{
+			name: "lint", args: text{"[<記事>...]", "[<article>...]"}, minArgs: 0, maxArgs: -1, articles: true, need: needWorkspace,
+			summary: text{"記事のメタデータとマークダウンの問題を検査する（問題があれば終了コード 6）", "check the articles for metadata and Markdown problems (exit code 6 if any)"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.BoolVar(&o.prose, "prose", false, text{
+					"日本語の文章（文体の混在、全角・半角、長い文、助詞の重複、用語の表記）も検査する",
+					"also check the Japanese prose (mixed styles, full/half width, long sentences, doubled particles, preferred terms)"}.String())
+			},
+			run: func(s *session, args []string) error {
+				var err error
+				report.Diagnostics, err = lintArticles(s.workspace, s.articlesDir, s.profile, s.opts.prose, args)
+				return err
+			},
+		},
+		{
+			name: "create", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary:  text{"記事を新しく投稿し、投稿IDを記事に記録する", "publish articles as new posts and record their post IDs"},
+			flags:    publishFlags,
+			validate: validatePublish,
+			run: eachArticle("create", func(s *session, name string) (*articleResult, error) {
+				return publish(s.client, s.workspace, "create", name, s.opts.publish())
+			}),
+		},
+		{
+			name: "update", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary:  text{"記事の post_id の投稿を、変更のあるフィールドだけ更新する", "update the posts of the articles, sending only changed fields"},
+			flags:    publishFlags,
+			validate: validatePublish,
+			run: eachArticle("update", func(s *session, name string) (*articleResult, error) {
+				return publish(s.client, s.workspace, "update", name, s.opts.publish())
+			}),
+		},
+		{
+			name: "pull", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"WordPress 上の投稿内容で記事ファイルを上書きする", "overwrite the article files with the posts on WordPress"},
+			run:     eachArticle("pull", func(s *session, name string) (*articleResult, error) { return pull(s.client, s.workspace, name) }),
+		},
+		{
+			name: "diff", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"記事と WordPress 上の投稿の差分を表示する（差分があれば終了コード 1）", "show differences between the articles and the posts (exit code 1 if any)"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.BoolVar(&o.markdown, "markdown", false, text{
+					"本文を HTML ではなくマークダウンに戻して比較する",
+					"compare the content as Markdown instead of HTML"}.String())
+			},
+			run: eachArticle("diff", func(s *session, name string) (*articleResult, error) {
+				return diff(s.client, s.workspace, name, s.opts.markdown)
+			}),
+		},
+		{
+			name: "status", args: text{"[<記事>...]", "[<article>...]"}, minArgs: 0, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"記事ごとの同期状態を一覧表示する", "list the sync state of the articles"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.BoolVar(&o.json, "json", false, text{"-output json と同じ", "same as -output json"}.String())
+			},
+			run: func(s *session, args []string) error {
+				var err error
+				report.Statuses, err = status(s.client, s.workspace, args)
+				return err
+			},
+		},
+		{
+			name: "history", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"投稿のリビジョンを新しい順に表示する", "list the revisions of the posts, newest first"},
+			run:     eachArticle("history", func(s *session, name string) (*articleResult, error) { return history(s.client, s.workspace, name) }),
+		},
+		{
+			name: "rollback", args: text{"<記事>", "<article>"}, minArgs: 1, maxArgs: 1, articles: true, need: needClient,
+			summary: text{"投稿をリビジョンの内容に戻す", "restore the post to a revision"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.IntVar(&o.to, "to", 0, text{"戻すリビジョンのID（history で確認できます）", "revision ID to restore (see history)"}.String())
+				fs.BoolVar(&o.local, "local", false, text{"ローカルの記事ファイルも書き換える", "also rewrite the local article files"}.String())
+			},
+			validate: func(o *options) error {
+				if o.to == 0 {
+					return usageError("エラー: -to で戻すリビジョンを指定してください（history で確認できます）")
+				}
+				return nil
+			},
+			run: eachArticle("rollback", func(s *session, name string) (*articleResult, error) {
+				return rollback(s.client, s.workspace, name, s.opts.to, s.opts.local)
+			}),
+		},
+		{
+			name: "unpublish", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"投稿を下書きまたは非公開にする", "make the posts drafts or private"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.StringVar(&o.status, "status", "draft", text{"unpublish 後のステータス（draft または private）", "status after unpublishing (draft or private)"}.String())
+			},
+			validate: func(o *options) error {
+				if o.status != "draft" && o.status != "private" {
+					return usageError("エラー: unpublish の -status には draft または private を指定してください: %s", o.status)
+				}
+				return nil
+			},
+			run: eachArticle("unpublish", func(s *session, name string) (*articleResult, error) {
+				return unpublish(s.client, s.workspace, name, s.opts.status)
+			}),
+		},
+		{
+			name: "delete", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"投稿をゴミ箱に移す", "move the posts to the trash"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.BoolVar(&o.force, "force", false, text{"ゴミ箱を経由せずに完全に削除する", "delete permanently instead of moving to the trash"}.String())
+			},
+			run: eachArticle("delete", func(s *session, name string) (*articleResult, error) {
+				return deleteArticle(s.client, s.workspace, name, s.opts.force)
+			}),
+		},
+		{
+			name: "sync", minArgs: 0, maxArgs: 0, need: needClient,
+			summary: text{"変更のあるすべての記事を投稿・更新する", "publish or update every changed article"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				publishFlags(fs, o)
+				fs.BoolVar(&o.prune, "prune", false, text{
+					"ローカルで削除された記事の投稿をゴミ箱に移す",
+					"move posts whose articles were deleted locally to the trash"}.String())
+			},
+			validate: validatePublish,
+			run: func(s *session, args []string) error {
+				var err error
+				report.Results, err = syncArticles(s.client, s.workspace, s.opts.publish(), s.opts.prune)
+				return err
+			},
+		},
+		{
+			name: "promote", args: articleArgs, minArgs: 1, maxArgs: -1, articles: true, need: needClient,
+			summary: text{"-from のプロファイルの投稿を -profile のサイトにコピーする", "copy the posts from the -from profile to the -profile site"},
+			flags: func(fs *flag.FlagSet, o *options) {
+				fs.StringVar(&o.from, "from", "", text{"コピー元にするプロファイル", "profile to copy from"}.String())
+			},
+			validate: func(o *options) error {
+				if o.from == "" {
+					return usageError("エラー: promote には -from でコピー元のプロファイルを指定してください")
+				}
+				return nil
+			},
+			run: eachArticle("promote", func(s *session, name string) (*articleResult, error) {
+				return promote(s.client, s.workspace, s.config, s.opts.from, name)
+			}),
+		},
+		{
+			name: "login", minArgs: 0, maxArgs: 0, need: needProfile,
+			summary: text{"認証情報を確認して保存先に保存する", "verify credentials and save them to the credential store"},
+			run:     func(s *session, args []string) error { return login(s.profile) },
+		},
+		{
+			name: "logout", minArgs: 0, maxArgs: 0, need: needProfile,
+			summary: text{"保存した認証情報を削除する", "erase the saved credentials"},
+			run:     func(s *session, args []string) error { return logout(s.profile) },
+		},
+		{
+			name: "version", minArgs: 0, maxArgs: 0, need: needNothing,
+			summary: text{"バージョンを表示する", "print the version"},
+			run:     func(s *session, args []string) error { return printVersion() },
+		},
+		{
+			name: "help", args: text{"[<コマンド>]", "[<command>]"}, minArgs: 0, maxArgs: 1, need: needNothing,
+			summary: text{"使い方を表示する", "show help"},
+			run:     func(s *session, args []string) error { return help(args) },
+		},
+		{
+			name: "completion", args: text{"bash|zsh|fish", "bash|zsh|fish"}, minArgs: 1, maxArgs: 1, need: needNothing,
+			summary: text{"シェルの補完スクリプトを出力する", "print a shell completion script"},
+			run:     func(s *session, args []string) error { return completion(args[0]) },
+		},
+		{
+			name: completeCommand, minArgs: 0, maxArgs: -1, need: needNothing, hidden: true,
+			run: func(s *session, args []string) error { return complete(args) },
+		},
+	}
+}
+
+// publishFlags は create・update・sync のフラグを定義します
+func publishFlags(fs *flag.FlagSet, o *options) {
+	fs.BoolVar(&o.force, "force", false, text{
+		"変更のないフィールドも含めてすべて送信する",
+		"send every field, including unchanged ones"}.String())
+	fs.BoolVar(&o.ours, "ours", false, text{
+		"WordPress 上で変更されていてもローカルの内容で上書きする",
+		"overwrite the post with the local article even if it was edited on WordPress"}.String())
+	fs.BoolVar(&o.theirs, "theirs", false, text{
+		"WordPress 上で変更されている場合はその内容をローカルに取り込む",
+		"pull the post into the local article if it was edited on WordPress"}.String())
+}
+
+func validatePublish(o *options) error {
+	if o.ours && o.theirs {
+		return usageError("-ours と -theirs は同時に指定できません")
+	}
+	return nil
+}
+
+// eachArticle は記事ごとに f を実行するコマンドを作成します。同じクライアントを使うことで、
+// カテゴリー・タグ・画像の取得結果を記事間で共有します。最初に失敗した記事で止めます。
+func eachArticle(action string, f func(s *session, name string) (*articleResult, error)) func(*session, []string) error {
+	return func(s *session, names []string) error {
+		for _, name := range names {
+			if len(names) > 1 {
+				fmt.Fprintf(stdout, "== %s\n", name)
+			}
+			result, err := f(s, name)
+			if err != nil {
+				report.Results = append(report.Results, failedResult(name, action, err))
+				return err
+			}
+			report.Results = append(report.Results, result)
+		}
+		return nil
+	}
+}
+
+// lookupCommand は名前からコマンドを探します。見つからない場合は nil を返します。
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// parseCommandLine はコマンドライン引数を解析し、コマンドとその引数を返します。
+// 共通のフラグはコマンドの前後どちらにも、コマンドのフラグはコマンドより後の記事名の前後どちらにも指定できます。
+// -h・-help の場合は使い方を表示して、コマンドを nil で返します。
+func parseCommandLine(args []string, g *globalOptions, o *options) (*command, []string, error) {
+	fs := newFlagSet("")
+	g.register(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printUsage(stdout)
+			return nil, nil, nil
+		}
+		return nil, nil, flagError(err, "")
+	}
+	if fs.NArg() == 0 {
+		printUsage(stdout)
+		return nil, nil, usageError("%s", text{"コマンドを指定してください", "no command given"})
+	}
+
+	c := lookupCommand(fs.Arg(0))
+	if c == nil {
+		return nil, nil, usageError("不正なコマンド: %s（%s help でコマンドの一覧を表示します）", fs.Arg(0), progName)
+	}
+	report.Command = c.name
+	if c.name == completeCommand {
+		// 補完中の単語はフラグとして解析しない
+		return c, fs.Args()[1:], nil
+	}
+
+	cfs := newFlagSet(c.name)
+	g.register(cfs)
+	if c.flags != nil {
+		c.flags(cfs, o)
+	}
+	rest, err := parseInterspersed(cfs, fs.Args()[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			printCommandUsage(stdout, c)
+			return nil, nil, nil
+		}
+		return nil, nil, flagError(err, c.name)
+	}
+
+	if len(rest) < c.minArgs || (c.maxArgs >= 0 && len(rest) > c.maxArgs) {
+		return nil, nil, usageError("%s: %s %s %s %s（%s help %s）", text{"使用方法", "usage"}, progName, c.name, text{"[フラグ]", "[flags]"}, c.args, progName, c.name)
+	}
+	// status -json は -output json の別名
+	if o.json {
+		g.output = "json"
+	}
+	if err := g.validate(); err != nil {
+		return nil, nil, err
+	}
+	if c.validate != nil {
+		if err := c.validate(o); err != nil {
+			return nil, nil, err
+		}
+	}
+	return c, rest, nil
+}
+
+// parseInterspersed はフラグと引数が混在したコマンドライン引数を解析し、引数を返します。"--" 以降はすべて引数です。
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		consumed := len(args) - fs.NArg()
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(rest, fs.Args()...), nil
+		}
+		if fs.NArg() == 0 {
+			return rest, nil
+		}
+		rest = append(rest, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// flagError はフラグの解析エラーを使い方の誤りにします。
+// コマンドの前に指定したフラグがいずれかのコマンドのフラグの場合は、コマンドの後に指定するよう案内します。
+func flagError(err error, name string) error {
+	const undefined = "flag provided but not defined: -"
+	msg := err.Error()
+	if !strings.HasPrefix(msg, undefined) {
+		return usageError("%v", err)
+	}
+	flagName := strings.TrimPrefix(msg, undefined)
+	if name != "" {
+		return usageError("%s: -%s（%s help %s）", text{"不明なフラグ", "unknown flag"}, flagName, progName, name)
+	}
+
+	var owners []string
+	for _, c := range commands {
+		if c.flags == nil {
+			continue
+		}
+		fs := newFlagSet(c.name)
+		c.flags(fs, &options{})
+		if fs.Lookup(flagName) != nil {
+			owners = append(owners, c.name)
+		}
+	}
+	if len(owners) == 0 {
+		return usageError("%s: -%s（%s help）", text{"不明なフラグ", "unknown flag"}, flagName, progName)
+	}
+	return usageError("-%s は %s のフラグです。コマンドの後に指定してください（例: %s %s -%s ...）",
+		flagName, strings.Join(owners, "、"), progName, owners[0], flagName)
+}
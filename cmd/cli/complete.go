@@ -0,0 +1,186 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"wp/internal/wp"
+
+	"github.com/joho/godotenv"
+)
+
+// completeCommand は補完スクリプトから呼び出す隠しコマンドです。
+// 入力済みの単語と入力中の単語を引数に受け取り、候補を1行に1つ出力します。
+const completeCommand = "__complete"
+
+// completion は completion コマンドです。シェルの補完スクリプトを標準出力に出力します。
+func completion(shell string) error {
+	fn := "_" + regexp.MustCompile(`[^A-Za-z0-9_]`).ReplaceAllString(progName, "_") + "_complete"
+	var script string
+	switch shell {
+	case "bash":
+		script = fmt.Sprintf(`# %[1]s の bash 補完（~/.bashrc に source <(%[1]s completion bash) を追加します）
+%[2]s() {
+	local IFS=$'\n'
+	COMPREPLY=($(%[1]s %[3]s "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null))
+}
+complete -o default -F %[2]s %[1]s
+`, progName, fn, completeCommand)
+	case "zsh":
+		script = fmt.Sprintf(`#compdef %[1]s
+# %[1]s の zsh 補完（~/.zshrc の compinit の後に source <(%[1]s completion zsh) を追加します）
+%[2]s() {
+	local -a candidates
+	candidates=(${(f)"$(%[1]s %[3]s "${(@)words[2,CURRENT]}" 2>/dev/null)"})
+	if (( ${#candidates} )); then
+		compadd -Q -- "${candidates[@]}"
+	else
+		_files
+	fi
+}
+compdef %[2]s %[1]s
+`, progName, fn, completeCommand)
+	case "fish":
+		script = fmt.Sprintf(`# %[1]s の fish 補完（%[1]s completion fish > ~/.config/fish/completions/%[1]s.fish）
+function %[2]s
+	set -l tokens (commandline -opc)
+	set -e tokens[1]
+	%[1]s %[3]s $tokens (commandline -ct) 2>/dev/null
+end
+complete -c %[1]s -f -a '(%[2]s)'
+`, progName, fn, completeCommand)
+	default:
+		return usageError("bash、zsh、fish のいずれかを指定してください: %s", shell)
+	}
+	fmt.Fprint(os.Stdout, script)
+	return nil
+}
+
+// complete は補完の候補を出力します。args の最後の要素が入力中の単語です。
+func complete(args []string) error {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	current := args[len(args)-1]
+
+	g := newGlobalOptions()
+	fs := newFlagSet("")
+	g.register(fs)
+
+	// 入力済みの単語からコマンドと、値を待っているフラグを調べる
+	var c *command
+	var pending *flag.Flag
+	for _, word := range args[:len(args)-1] {
+		if pending != nil {
+			pending.Value.Set(word)
+			pending = nil
+			continue
+		}
+		if strings.HasPrefix(word, "-") && word != "-" && word != "--" {
+			name, value, hasValue := strings.Cut(strings.TrimLeft(word, "-"), "=")
+			f := fs.Lookup(name)
+			switch {
+			case f == nil:
+			case hasValue:
+				f.Value.Set(value)
+			case !isBoolFlag(f):
+				pending = f
+			}
+			continue
+		}
+		if c == nil {
+			if c = lookupCommand(word); c == nil {
+				return nil
+			}
+			if c.flags != nil {
+				c.flags(fs, &options{})
+			}
+		}
+	}
+
+	var candidates []string
+	switch {
+	case pending != nil:
+		candidates = flagValues(pending.Name, g)
+	case strings.HasPrefix(current, "-"):
+		fs.VisitAll(func(f *flag.Flag) {
+			candidates = append(candidates, "-"+f.Name)
+		})
+	case c == nil || c.name == "help":
+		for _, cmd := range commands {
+			if !cmd.hidden {
+				candidates = append(candidates, cmd.name)
+			}
+		}
+	case c.name == "completion":
+		candidates = []string{"bash", "zsh", "fish"}
+	case c.articles:
+		candidates = articleCandidates(g)
+	case c.name == "new":
+		candidates = articleDirCandidates(g)
+	}
+
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, current) {
+			fmt.Fprintln(os.Stdout, candidate)
+		}
+	}
+	return nil
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagValues はフラグの値の候補を返します
+func flagValues(name string, g *globalOptions) []string {
+	switch name {
+	case "output", "log-format":
+		return []string{"text", "json"}
+	case "status":
+		return []string{"draft", "private"}
+	case "profile", "from":
+		if config, _, _ := loadConfig(g); config != nil {
+			return config.ProfileNames()
+		}
+	}
+	return nil
+}
+
+// articleCandidates は記事の置き場所にある記事名を返します。設定の誤りなどで一覧を取得できない場合は空です。
+func articleCandidates(g *globalOptions) []string {
+	config, base, err := loadConfig(g)
+	if err != nil {
+		return nil
+	}
+	godotenv.Load(envFiles(config, base)...)
+
+	var profile wp.Profile
+	if config != nil {
+		profile, _ = config.Profile(g.profile)
+	}
+	ws, _ := newWorkspace(g.articles, g.images, profile, base)
+	names, err := wp.ListArticlesFS(ws.Articles)
+	if err != nil {
+		return nil
+	}
+	return names
+}
+
+// articleDirCandidates は記事のあるディレクトリを "/" で終わる名前で返します
+func articleDirCandidates(g *globalOptions) []string {
+	var dirs []string
+	seen := map[string]bool{}
+	for _, name := range articleCandidates(g) {
+		if dir := path.Dir(name); dir != "." && !seen[dir] {
+			seen[dir] = true
+			dirs = append(dirs, dir+"/")
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"wp/internal/wp"
+)
+
+// unpublish は投稿を下書きまたは非公開にし、記事の Status にも記録します
+func unpublish(client *wp.Client, ws *wp.Workspace, filename, status string) (*articleResult, error) {
+	metadata, body, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+	if metadata.PostID == 0 {
+		return nil, fmt.Errorf("エラー: %w", wp.ErrNotPublished)
+	}
+
+	postType, err := wp.ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, fmt.Errorf("投稿タイプ取得エラー: %w", err)
+	}
+	resp, err := client.UpdatePostFields(postType.RestBase, metadata.PostID, map[string]interface{}{"status": status})
+	if err != nil {
+		return nil, fmt.Errorf("更新エラー: %w", err)
+	}
+
+	// 次の update で公開に戻らないよう、ステータスを記事にも記録する
+	metadata.Status = status
+	metadata.Sync = wp.NewSyncState(resp)
+	metadata.Sync.LocalHash = wp.HashArticle(metadata, body)
+	if err := wp.UpdateMetadataFS(ws.Articles, filename, metadata); err != nil {
+		return nil, fmt.Errorf("メタデータ更新エラー: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "投稿ID %d を %s にしました\n", resp.ID, status)
+	return &articleResult{Article: filename, OK: true, Action: "unpublish", PostID: resp.ID, Link: resp.Link, Status: resp.Status}, nil
+}
+
+// deleteArticle は投稿をゴミ箱に移します。force が true の場合は完全に削除し、記事の post_id を取り除きます。
+func deleteArticle(client *wp.Client, ws *wp.Workspace, filename string, force bool) (*articleResult, error) {
+	metadata, body, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+	if metadata.PostID == 0 {
+		return nil, fmt.Errorf("エラー: %w", wp.ErrNotPublished)
+	}
+
+	postType, err := wp.ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, fmt.Errorf("投稿タイプ取得エラー: %w", err)
+	}
+	postID := metadata.PostID
+	if err := client.DeletePost(postType.RestBase, postID, force); err != nil {
+		return nil, fmt.Errorf("削除エラー: %w", err)
+	}
+
+	if force {
+		// 投稿は残っていないため、次の create で新しく投稿できるようにする
+		metadata.PostID = 0
+		metadata.Sync = nil
+		metadata.Status = ""
+		if err := ws.RecordPost(filename, 0, ""); err != nil {
+			return nil, err
+		}
+	} else {
+		// ゴミ箱から戻せるよう post_id は残し、誤って update しないよう Status で示す
+		metadata.Status = "trash"
+		if metadata.Sync != nil {
+			metadata.Sync.LocalHash = wp.HashArticle(metadata, body)
+		}
+	}
+	if err := wp.UpdateMetadataFS(ws.Articles, filename, metadata); err != nil {
+		return nil, fmt.Errorf("メタデータ更新エラー: %w", err)
+	}
+
+	if force {
+		fmt.Fprintf(stdout, "投稿ID %d を完全に削除しました\n", postID)
+		return &articleResult{Article: filename, OK: true, Action: "delete", PostID: postID}, nil
+	}
+	fmt.Fprintf(stdout, "投稿ID %d をゴミ箱に移しました\n", postID)
+	return &articleResult{Article: filename, OK: true, Action: "trash", PostID: postID, Status: "trash"}, nil
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"wp/internal/wp"
+)
+
+// diff はローカルの記事と WordPress 上の投稿の差分を表示し、差分があるかを結果の Differs で返します
+func diff(client *wp.Client, ws *wp.Workspace, filename string, asMarkdown bool) (*articleResult, error) {
+	metadata, content, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+
+	d, err := wp.DiffArticle(client, ws, filename, metadata, content, asMarkdown)
+	if err != nil {
+		return nil, fmt.Errorf("差分取得エラー: %w", err)
+	}
+
+	result := &articleResult{Article: filename, OK: true, Action: "diff", PostID: metadata.PostID}
+	if d.Empty() {
+		fmt.Fprintln(stdout, "差分はありません")
+		return result, nil
+	}
+	fmt.Fprint(stdout, d)
+	result.Differs = true
+	result.Diff = d.String()
+	return result, nil
+}
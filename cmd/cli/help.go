@@ -0,0 +1,161 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+	"text/tabwriter"
+)
+
+// progName はヘルプと補完スクリプトに表示するプログラム名です
+var progName = filepath.Base(os.Args[0])
+
+// japanese はヘルプを日本語で表示する場合に true です。
+// LC_ALL、LC_MESSAGES、LANG のうち最初に設定されているものが ja で始まる場合、またはいずれも設定されていない場合に日本語にします。
+var japanese = isJapanese()
+
+func isJapanese() bool {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return strings.HasPrefix(v, "ja")
+		}
+	}
+	return true
+}
+
+// text は日本語と英語のヘルプの文言です
+type text struct {
+	ja, en string
+}
+
+func (t text) String() string {
+	if japanese {
+		return t.ja
+	}
+	return t.en
+}
+
+// printUsage はコマンドの一覧と共通のフラグを表示します
+func printUsage(w io.Writer) {
+	g := newGlobalOptions()
+	fs := newFlagSet("")
+	g.register(fs)
+
+	fmt.Fprintf(w, "%s: %s %s\n\n", text{"使用方法", "Usage"}, progName, text{"[フラグ] <コマンド> [引数...]", "[flags] <command> [args...]"})
+	fmt.Fprintf(w, "%s:\n", text{"コマンド", "Commands"})
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		if !c.hidden {
+			fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.summary)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\n%s:\n", text{"共通のフラグ（コマンドの前後どちらにも指定できます）", "Global flags (before or after the command)"})
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprintf(w, "\n%s\n", text{
+		fmt.Sprintf("各コマンドのフラグは「%s help <コマンド>」で確認できます。", progName),
+		fmt.Sprintf("Run '%s help <command>' for the flags of each command.", progName),
+	})
+}
+
+// printCommandUsage はコマンドの使い方とフラグを表示します
+func printCommandUsage(w io.Writer, c *command) {
+	fmt.Fprintf(w, "%s: %s %s %s %s\n\n", text{"使用方法", "Usage"}, progName, c.name, text{"[フラグ]", "[flags]"}, c.args)
+	fmt.Fprintf(w, "%s\n", c.summary)
+	if c.flags != nil {
+		fs := newFlagSet(c.name)
+		c.flags(fs, &options{})
+		fmt.Fprintf(w, "\n%s:\n", text{"フラグ", "Flags"})
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+	fmt.Fprintf(w, "\n%s\n", text{
+		fmt.Sprintf("共通のフラグは「%s help」で確認できます。", progName),
+		fmt.Sprintf("Run '%s help' for the global flags.", progName),
+	})
+}
+
+// help は help コマンドです。引数にコマンドを指定した場合はそのコマンドの使い方を表示します。
+func help(args []string) error {
+	if len(args) == 0 {
+		printUsage(stdout)
+		return nil
+	}
+	c := lookupCommand(args[0])
+	if c == nil {
+		return usageError("不正なコマンド: %s", args[0])
+	}
+	printCommandUsage(stdout, c)
+	return nil
+}
+
+// version はビルド時に -ldflags "-X main.version=v1.2.3" で設定するバージョンです
+var version string
+
+// versionInfo はバージョンとビルドの情報です
+type versionInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Time      string `json:"time,omitempty"`
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// buildVersion は version と、go build が埋め込んだモジュールのバージョン・コミットからバージョンの情報を作成します
+func buildVersion() *versionInfo {
+	info := &versionInfo{Version: version}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		if info.Version == "" {
+			info.Version = "dev"
+		}
+		return info
+	}
+	info.GoVersion = build.GoVersion
+	if info.Version == "" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	if info.Version == "" {
+		info.Version = "dev"
+	}
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time = s.Value
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// printVersion は version コマンドです
+func printVersion() error {
+	info := buildVersion()
+	report.Version = info
+	fmt.Fprintf(stdout, "%s %s", progName, info.Version)
+	// go build が埋め込む疑似バージョンにはコミットが含まれる
+	if revision := info.Revision[:min(len(info.Revision), 12)]; revision != "" && !strings.Contains(info.Version, revision) {
+		if info.Modified {
+			revision += "+dirty"
+		}
+		fmt.Fprintf(stdout, " (%s %s)", revision, info.Time)
+	}
+	fmt.Fprintf(stdout, " %s\n", info.GoVersion)
+	return nil
+}
+
+// newFlagSet はエラーの表示と終了を呼び出し側で行う FlagSet を作成します
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	return fs
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"wp/internal/wp"
+)
+
+// history は投稿のリビジョンを新しい順に表示します
+func history(client *wp.Client, ws *wp.Workspace, filename string) (*articleResult, error) {
+	metadata, _, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+	if metadata.PostID == 0 {
+		return nil, fmt.Errorf("エラー: %w", wp.ErrNotPublished)
+	}
+
+	postType, err := wp.ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, fmt.Errorf("投稿タイプ取得エラー: %w", err)
+	}
+	revisions, err := wp.GetRevisions(client, postType.RestBase, metadata.PostID)
+	if err != nil {
+		return nil, err
+	}
+	result := &articleResult{Article: filename, OK: true, Action: "history", PostID: metadata.PostID, Revisions: []revisionResult{}}
+	if len(revisions) == 0 {
+		fmt.Fprintln(stdout, "リビジョンはありません")
+		return result, nil
+	}
+
+	authors := make(map[int]string)
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "リビジョン\t日時\t投稿者\tタイトル\t文字数")
+	for _, rev := range revisions {
+		name, ok := authors[rev.Author]
+		if !ok {
+			name = fmt.Sprint(rev.Author)
+			if user, err := wp.GetUser(client, rev.Author); err == nil {
+				name = user.Name
+			}
+			authors[rev.Author] = name
+		}
+		length := utf8.RuneCountInString(rev.Content.Raw)
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%d\n",
+			rev.ID, strings.Replace(rev.Date, "T", " ", 1), name, rev.Title.Raw, length)
+		result.Revisions = append(result.Revisions, revisionResult{
+			ID: rev.ID, Date: rev.Date, Author: rev.Author, AuthorName: name, Title: rev.Title.Raw, Length: length,
+		})
+	}
+	return result, w.Flush()
+}
+
+// rollback は投稿をリビジョンの内容に戻します。local が true の場合はローカルの記事ファイルも書き換えます。
+func rollback(client *wp.Client, ws *wp.Workspace, filename string, revisionID int, local bool) (*articleResult, error) {
+	metadata, _, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+	if metadata.PostID == 0 {
+		return nil, fmt.Errorf("エラー: %w", wp.ErrNotPublished)
+	}
+
+	postType, err := wp.ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, fmt.Errorf("投稿タイプ取得エラー: %w", err)
+	}
+	revision, err := wp.GetRevision(client, postType.RestBase, metadata.PostID, revisionID)
+	if err != nil {
+		return nil, err
+	}
+	if revision.Parent != metadata.PostID {
+		return nil, fmt.Errorf("エラー: リビジョン %d は投稿ID %d のものではありません", revisionID, metadata.PostID)
+	}
+
+	resp, err := wp.RestoreRevision(client, postType.RestBase, metadata.PostID, revision)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(stdout, "リビジョン %d（%s）に戻しました。投稿ID: %d\n", revision.ID, strings.Replace(revision.Date, "T", " ", 1), resp.ID)
+	result := &articleResult{Article: filename, OK: true, Action: "rollback", PostID: resp.ID, Link: resp.Link, Status: resp.Status, RevisionID: revision.ID}
+
+	if !local {
+		const warning = "ローカルの記事は変更していません。このまま update すると競合として検出されます（-local で記事も戻せます）"
+		fmt.Fprintln(stdout, warning)
+		result.Warnings = append(result.Warnings, warning)
+		return result, nil
+	}
+
+	body := wp.ConvertHTMLToMarkdown(revision.Content.Raw)
+	metadata.Title = revision.Title.Raw
+	if metadata.Excerpt != "" || revision.Excerpt.Raw != "" {
+		metadata.Excerpt = revision.Excerpt.Raw
+	}
+	metadata.Sync = wp.NewSyncState(resp)
+	metadata.Sync.LocalHash = wp.HashArticle(metadata, body)
+	if err := wp.WriteArticleFS(ws.Articles, filename, metadata, body); err != nil {
+		return nil, fmt.Errorf("記事書き込みエラー: %w", err)
+	}
+	fmt.Fprintf(stdout, "ローカルの記事も書き換えました: %s\n", filename)
+	return result, nil
+}
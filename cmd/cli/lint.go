@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"wp/internal/lint"
+	"wp/internal/wp"
+)
+
+// lintArticles は lint コマンドです。記事を検査して問題を「ファイル:行: 内容 [種類]」の形式で表示し、問題があればエラーを返します。
+// names が空の場合はすべての記事を検査します。prose が true の場合は日本語の文章も検査します。
+func lintArticles(ws *wp.Workspace, articlesDir string, profile wp.Profile, prose bool, names []string) ([]lint.Diagnostic, error) {
+	linter := lint.New(ws)
+	linter.Languages = profile.CodeLanguages
+	linter.Prose = prose
+	linter.MaxSentenceLength = profile.MaxSentenceLength
+	if len(profile.PreferredTerms) > 0 {
+		linter.Terms = make(map[string]string)
+		for wrong, right := range lint.DefaultTerms {
+			linter.Terms[wrong] = right
+		}
+		for wrong, right := range profile.PreferredTerms {
+			linter.Terms[wrong] = right
+		}
+	}
+	diags, err := linter.Lint(names)
+	if err != nil {
+		return nil, err
+	}
+	for i := range diags {
+		diags[i].File = filepath.Join(articlesDir, filepath.FromSlash(diags[i].File))
+		fmt.Fprintln(stdout, diags[i])
+	}
+	if len(diags) > 0 {
+		return diags, withCode("invalid_article", fmt.Errorf("%d 件の問題があります", len(diags)))
+	}
+	fmt.Fprintln(stdout, "問題はありません")
+	return diags, nil
+}
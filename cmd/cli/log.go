@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+
+	"wp/internal/wp"
+)
+
+// logger は -v・-vv を指定した場合のロガーです。指定しない場合は nil でログを出力しません。
+var logger *slog.Logger
+
+// setupLogger は -v で公開処理の各段階と HTTP のリクエストごとの結果を、
+// -vv ではヘッダーと本文も標準エラー出力に出力するよう設定します。format は text または json です。
+func setupLogger(verbose, veryVerbose bool, format string) error {
+	if !verbose && !veryVerbose {
+		return nil
+	}
+	opts := &slog.HandlerOptions{Level: slog.LevelInfo}
+	if veryVerbose {
+		opts.Level = slog.LevelDebug
+	}
+
+	var handler slog.Handler
+	switch format {
+	case "", "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("不正なログ形式: %s（text または json を指定してください）", format)
+	}
+	logger = slog.New(handler)
+
+	next := transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	transport = &wp.TracingTransport{Transport: next, Logger: logger}
+	return nil
+}
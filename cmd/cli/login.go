@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"wp/internal/wp"
+)
+
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine はプロンプトを表示して1行読み取ります
+func readLine(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("入力の読み取りエラー: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readSecret は入力内容を表示せずに1行読み取ります。端末でない場合はそのまま読み取ります。
+func readSecret(prompt string) (string, error) {
+	stty := func(arg string) error {
+		cmd := exec.Command("stty", arg)
+		cmd.Stdin = os.Stdin
+		return cmd.Run()
+	}
+	if stty("-echo") == nil {
+		defer func() {
+			stty("echo")
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+	return readLine(prompt)
+}
+
+var cachedPassphrase string
+
+// passphrase は認証情報ファイルのパスフレーズを WP_CREDENTIAL_PASSPHRASE または入力から取得します
+func passphrase() (string, error) {
+	if cachedPassphrase != "" {
+		return cachedPassphrase, nil
+	}
+	p := os.Getenv("WP_CREDENTIAL_PASSPHRASE")
+	if p == "" {
+		var err error
+		if p, err = readSecret("認証情報ファイルのパスフレーズ: "); err != nil {
+			return "", err
+		}
+	}
+	cachedPassphrase = p
+	return p, nil
+}
+
+// newProfileClient はプロファイルの保存先から認証情報を読み取ってクライアントを作成します。
+// -replay の場合は認証情報を読み取らず、記録した通信を再生するクライアントを作成します。
+func newProfileClient(profile wp.Profile) (*wp.Client, error) {
+	if replayer != nil {
+		site := profile.URL
+		if site == "" {
+			site = replayer.Site()
+		}
+		client := wp.NewClientWithAuth(site, nil)
+		client.HTTPClient.Transport = transport
+		client.Logger = logger
+		return client, nil
+	}
+
+	store, err := profile.CredentialStore(passphrase)
+	if err != nil {
+		return nil, withCode("config", err)
+	}
+	cred, err := profile.Credentials(store)
+	if err != nil {
+		return nil, withCode("auth", err)
+	}
+	client, err := profile.NewClient(cred)
+	if err != nil {
+		return nil, withCode("config", err)
+	}
+	useTransport(client)
+	return client, nil
+}
+
+// useTransport は -record・-v などで設定したトランスポートとロガーをクライアントに設定します
+func useTransport(client *wp.Client) {
+	if transport != nil {
+		client.HTTPClient.Transport = transport
+	}
+	client.Logger = logger
+}
+
+// login は認証情報を入力させ、/wp/v2/users/me で確認してから保存先に保存します。
+// 保存先が環境変数の場合は確認だけを行います。
+func login(profile wp.Profile) error {
+	store, err := profile.CredentialStore(passphrase)
+	if err != nil {
+		return err
+	}
+
+	_, fromEnv := store.(*wp.EnvCredentialStore)
+	var cred wp.Credentials
+	if fromEnv {
+		if cred, err = profile.Credentials(store); err != nil {
+			return err
+		}
+	} else {
+		cred.Username = profile.Username
+		if cred.Username == "" && profile.Auth != wp.AuthBearer {
+			if cred.Username, err = readLine(fmt.Sprintf("%s のユーザー名: ", profile.URL)); err != nil {
+				return err
+			}
+		}
+		label := "パスワード"
+		switch profile.Auth {
+		case wp.AuthApplicationPassword:
+			label = "アプリケーションパスワード"
+		case wp.AuthBearer:
+			label = "アクセストークン"
+		}
+		secret, err := readSecret(label + ": ")
+		if err != nil {
+			return err
+		}
+		if profile.Auth == wp.AuthBearer {
+			cred.Token = secret
+		} else {
+			cred.Password = secret
+		}
+	}
+
+	client, err := profile.NewClient(cred)
+	if err != nil {
+		return err
+	}
+	useTransport(client)
+	user, err := wp.GetCurrentUser(client)
+	if err != nil {
+		return fmt.Errorf("ログインできませんでした: %w", err)
+	}
+
+	if fromEnv {
+		fmt.Fprintf(stdout, "環境変数の認証情報を確認しました: %s（%s）\n", user.Name, user.Username)
+		return nil
+	}
+	if err := store.Store(profile.URL, cred); err != nil {
+		return fmt.Errorf("認証情報の保存エラー: %w", err)
+	}
+	fmt.Fprintf(stdout, "ログインしました: %s（%s）\n", user.Name, user.Username)
+	return nil
+}
+
+// logout は保存先からプロファイルの認証情報を削除します
+func logout(profile wp.Profile) error {
+	store, err := profile.CredentialStore(passphrase)
+	if err != nil {
+		return err
+	}
+	if err := store.Erase(profile.URL); err != nil {
+		return fmt.Errorf("認証情報の削除エラー: %w", err)
+	}
+	fmt.Fprintf(stdout, "%s の認証情報を削除しました\n", profile.URL)
+	return nil
+}
@@ -1,9 +1,11 @@
 package main
 
 import (
-	"flag"
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"wp/internal/wp"
 
@@ -11,105 +13,222 @@ import (
 )
 
 func main() {
-	// コマンドライン引数の解析
-	flag.Parse()
-	args := flag.Args()
+	finish(run(os.Args[1:]))
+}
 
-	if len(args) != 2 {
-		fmt.Println("使用方法: go run cmd/cli [command] [マークダウンファイル名]")
-		fmt.Println("例: go run cmd/cli create article1")
-		fmt.Println("    go run cmd/cli update article1")
-		os.Exit(1)
+// run はコマンドライン引数を解析し、設定の読み込み、クライアントの作成を経てコマンドを実行します。
+// WordPress に接続する前に、コマンド・フラグ・記事ファイルをすべて確かめます。
+func run(args []string) error {
+	g := newGlobalOptions()
+	opts := &options{}
+	c, args, err := parseCommandLine(args, g, opts)
+	if err != nil || c == nil {
+		return err
+	}
+	if err := setupOutput(g.output); err != nil {
+		return err
 	}
 
-	command := args[0]
-	filename := args[1]
+	s := &session{opts: opts}
+	if c.need == needNothing {
+		return c.run(s, args)
+	}
 
-	err := godotenv.Load()
+	config, base, err := loadConfig(g)
 	if err != nil {
-		fmt.Printf("Error loading .env file: %v\n", err)
-		return
+		return err
 	}
+	s.config = config
 
-	client := wp.NewClient(
-		os.Getenv("WP_URL"),
-		os.Getenv("USER_NAME"),
-		os.Getenv("USER_PASSWORD"),
-	)
+	// 設定ファイルがある場合、.env は認証情報を渡すためだけに使うので、なくてもよい
+	if err := godotenv.Load(envFiles(config, base)...); err != nil && config == nil && c.need != needWorkspace {
+		return withCode("config", fmt.Errorf("Error loading .env file: %w", err))
+	}
 
-	// 指定されたファイル名の記事を読み込む
-	metadata, content, err := wp.ReadArticleFromMd(filename)
-	if err != nil {
-		fmt.Printf("記事読み取りエラー: %v\n", err)
-		return
+	// stateKey は記事の投稿IDと同期状態を読み書きするプロファイルです
+	var stateKey string
+	if config != nil {
+		if s.profile, err = config.Profile(g.profile); err != nil {
+			return withCode("config", err)
+		}
+		stateKey = config.StateKey(g.profile)
+	} else {
+		if g.profile != "" || c.name == "promote" {
+			return withCode("config", fmt.Errorf("プロファイルを使うには設定ファイル wp.json が必要です"))
+		}
+		s.profile = wp.Profile{
+			URL:              os.Getenv("WP_URL"),
+			Auth:             os.Getenv("WP_AUTH"),
+			Credential:       os.Getenv("WP_CREDENTIAL"),
+			CredentialHelper: os.Getenv("WP_CREDENTIAL_HELPER"),
+			CredentialFile:   os.Getenv("WP_CREDENTIAL_FILE"),
+		}
 	}
 
-	content, err = wp.ExtractAndUploadImages(client, content)
-	if err != nil {
-		fmt.Printf("画像アップロードエラー: %v\n", err)
-		return
+	s.workspace, s.articlesDir = newWorkspace(g.articles, g.images, s.profile, base)
+	s.workspace.Profile = stateKey
+	if c.articles {
+		for i, arg := range args {
+			name, err := articleName(s.articlesDir, arg)
+			if err != nil {
+				return withCode("usage", err)
+			}
+			exists, err := wp.ArticleExists(s.workspace.Articles, name)
+			if err != nil {
+				return withCode("invalid_article", err)
+			}
+			if !exists {
+				return withCode("invalid_article", fmt.Errorf("記事が見つかりません: %s（%s）", name, s.articlesDir))
+			}
+			args[i] = name
+		}
 	}
 
-	categoryIDs, err := wp.GetCategoryIDs(client, metadata.Category)
-	if err != nil {
-		fmt.Printf("カテゴリーID取得エラー: %v\n", err)
-		return
+	if c.need == needWorkspace {
+		return c.run(s, args)
+	}
+	// login・logout は保存先の認証情報を読み書きするため、記録した通信の再生では実行しない
+	if c.need == needProfile && g.replay != "" {
+		return usageError("-replay は %s では使えません", c.name)
 	}
 
-	var mediaID int
-	if metadata.Image != "" {
-		mediaID, err = wp.UploadFeaturedImage(client, metadata.Image)
+	if err := setupTransport(g.record, g.replay); err != nil {
+		return err
+	}
+	if err := setupLogger(g.verbose, g.vverbose, g.logFormat); err != nil {
+		return err
+	}
+	if c.need == needProfile {
+		return c.run(s, args)
+	}
+	if s.client, err = newProfileClient(s.profile); err != nil {
+		return err
+	}
+	if g.cache != "" {
+		cache, err := wp.LoadCache(g.cache, g.cacheTTL, s.client.BaseURL)
 		if err != nil {
-			fmt.Printf("画像アップロードエラー: %v\n", err)
-			return
+			return withCode("config", fmt.Errorf("キャッシュ読み込みエラー: %w", err))
 		}
+		s.client.Cache = cache
 	}
 
-	tagIDs, err := wp.GetTagIDs(client, metadata.Tag)
-	if err != nil {
-		fmt.Printf("タグID取得エラー: %v\n", err)
-		return
+	err = c.run(s, args)
+	if err := s.client.Cache.Save(); err != nil {
+		fmt.Fprintf(stdout, "キャッシュ保存エラー: %v\n", err)
 	}
+	return err
+}
 
-	post := wp.PostRequest{
-		Title:         metadata.Title,
-		Content:       wp.ConvertMarkdownToHTML(content),
-		Status:        "publish",
-		Slug:          metadata.Permalink,
-		Categories:    categoryIDs,
-		Tags:          tagIDs,
-		FeaturedMedia: mediaID,
+// loadConfig は -config またはカレントディレクトリから親へ探した wp.json を読み込み、そのディレクトリとともに返します。
+// 設定ファイルがない場合は nil と "." を返します。
+func loadConfig(g *globalOptions) (*wp.Config, string, error) {
+	path := g.config
+	if path == "" {
+		path = findConfig("wp.json")
+	}
+	if path == "" {
+		return nil, ".", nil
 	}
+	config, err := wp.LoadConfig(path)
+	if err != nil {
+		return nil, "", withCode("config", err)
+	}
+	if config == nil {
+		return nil, "", withCode("config", fmt.Errorf("設定ファイルが見つかりません: %s", path))
+	}
+	return config, filepath.Dir(path), nil
+}
 
-	var resp *wp.PostResponse
-	switch command {
-	case "create":
-		resp, err = client.CreatePost(post)
-		if err != nil {
-			fmt.Printf("投稿エラー: %v\n", err)
-			return
-		}
-		// メタデータにpost_idを追加して保存
-		metadata.PostID = resp.ID
-		if err := wp.UpdateMetadata(filename, metadata); err != nil {
-			fmt.Printf("メタデータ更新エラー: %v\n", err)
-			return
+// envFiles は読み込む .env を返します。設定ファイルがある場合は、そのディレクトリの .env を優先します。
+func envFiles(config *wp.Config, base string) []string {
+	if envFile := filepath.Join(base, ".env"); config != nil && base != "." {
+		if _, err := os.Stat(envFile); err == nil {
+			return []string{envFile}
 		}
-	case "update":
-		if metadata.PostID == 0 {
-			fmt.Println("エラー: この記事はまだ投稿されていません")
-			return
+	}
+	return []string{".env"}
+}
+
+// publishOptions は create/update の動作を指定します
+type publishOptions struct {
+	// force は変更のないフィールドも含めてすべて送信します
+	force bool
+	// resolution は競合時の解決方法です（"ours"、"theirs"、空の場合は中止）
+	resolution string
+}
+
+// publish は1つの記事を投稿または更新します
+func publish(client *wp.Client, ws *wp.Workspace, command, filename string, opts publishOptions) (*articleResult, error) {
+	publisher := &wp.Publisher{Client: client, Workspace: ws, Force: opts.force, Resolution: opts.resolution}
+
+	var result *wp.PublishResult
+	var err error
+	if command == "create" {
+		result, err = publisher.Create(context.Background(), filename)
+	} else {
+		result, err = publisher.Update(context.Background(), filename)
+	}
+	var conflict *wp.ConflictError
+	if errors.As(err, &conflict) {
+		fmt.Fprint(stdout, conflict.Diff())
+		return nil, fmt.Errorf("競合エラー: %w\n-ours でローカルの内容で上書き、-theirs で WordPress 上の内容を取り込みます", conflict)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, w := range result.Warnings {
+		fmt.Fprintf(stdout, "警告: %s\n", w)
+	}
+	r := &articleResult{
+		Article:           filename,
+		OK:                true,
+		Action:            command,
+		PostID:            result.PostID,
+		Link:              result.Link,
+		Status:            result.Status,
+		Created:           result.Created,
+		Pulled:            result.Pulled,
+		CreatedCategories: result.CreatedCategories,
+		CreatedTags:       result.CreatedTags,
+		UploadedMedia:     result.UploadedMedia,
+		Warnings:          result.Warnings,
+		Changes:           result.Changes,
+	}
+	if result.Pulled {
+		fmt.Fprintf(stdout, "投稿ID %d の内容を取り込みました: %s\n", result.PostID, filename)
+		return r, nil
+	}
+	if command == "update" && !opts.force {
+		if len(result.Changes) == 0 {
+			fmt.Fprintln(stdout, "変更はありません")
 		}
-		resp, err = client.UpdatePost(metadata.PostID, post)
-		if err != nil {
-			fmt.Printf("更新エラー: %v\n", err)
-			return
+		for _, change := range result.Changes {
+			fmt.Fprintf(stdout, "  %s\n", change)
 		}
-	default:
-		fmt.Printf("不正なコマンド: %s\n", command)
-		return
 	}
 
-	fmt.Printf("操作が成功しました。投稿ID: %d\n", resp.ID)
-	fmt.Printf("投稿URL: %s\n", resp.Link)
+	fmt.Fprintf(stdout, "操作が成功しました。投稿ID: %d\n", result.PostID)
+	fmt.Fprintf(stdout, "投稿URL: %s\n", result.Link)
+	return r, nil
+}
+
+// pull は WordPress 上の投稿内容でローカルの記事ファイルを上書きします
+func pull(client *wp.Client, ws *wp.Workspace, filename string) (*articleResult, error) {
+	metadata, _, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+
+	metadata, body, err := wp.PullPost(client, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("取得エラー: %w", err)
+	}
+
+	if err := wp.WriteArticleFS(ws.Articles, filename, metadata, body); err != nil {
+		return nil, fmt.Errorf("記事書き込みエラー: %w", err)
+	}
+
+	fmt.Fprintf(stdout, "投稿ID %d の内容を取得しました: %s\n", metadata.PostID, filename)
+	return &articleResult{Article: filename, OK: true, Action: "pull", PostID: metadata.PostID}, nil
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"wp/internal/wp"
+)
+
+// newArticle は new コマンドです。テンプレートから投稿IDのない記事ファイルを作成します。
+// arg がディレクトリ（posts_001-050 など）または空の場合は、そのディレクトリまたは最後の範囲のディレクトリに次の番号で作成します。
+func newArticle(ws *wp.Workspace, articlesDir, arg string, opts *options) (*articleResult, error) {
+	fsys := ws.Articles
+	name, err := newArticleName(fsys, articlesDir, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	var metadata wp.ArticleMetadata
+	var body string
+	if opts.template != "" {
+		content, err := os.ReadFile(opts.template)
+		if err != nil {
+			return nil, withCode("usage", fmt.Errorf("テンプレート読み取りエラー: %w", err))
+		}
+		if metadata, body, err = wp.ParseArticle(content); err != nil {
+			return nil, fmt.Errorf("テンプレート %s: %w", opts.template, err)
+		}
+		metadata, body = metadata.Clean(), strings.TrimLeft(body, "\n")
+	} else if metadata, body, err = wp.ArticleTemplateFS(fsys, name); err != nil {
+		return nil, err
+	}
+
+	// テンプレートのスラッグは使わず、記事ごとに決める
+	if opts.title != "" {
+		metadata.Title = opts.title
+	}
+	metadata.Permalink = opts.slug
+	if metadata.Permalink == "" {
+		metadata.Permalink = wp.SuggestSlug(metadata.Title)
+	}
+	result := &articleResult{Article: name, OK: true, Action: "new"}
+	if metadata.Permalink == "" {
+		result.Warnings = append(result.Warnings, "タイトルからスラッグを提案できませんでした（漢字・カタカナはローマ字にしません）。-slug で指定するか、Permalink を設定してください")
+	}
+
+	if err := wp.CreateArticleFS(fsys, name, metadata, body); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(stdout, "記事を作成しました: %s\n", filepath.Join(articlesDir, filepath.FromSlash(name)+".md"))
+	if metadata.Permalink != "" {
+		fmt.Fprintf(stdout, "スラッグ: %s\n", metadata.Permalink)
+	}
+	for _, w := range result.Warnings {
+		fmt.Fprintf(stdout, "警告: %s\n", w)
+	}
+	return result, nil
+}
+
+// newArticleName は new コマンドの引数から作成する記事名を決めます。
+// 既存のディレクトリ、末尾が "/" のパス、posts_001-050 のような範囲のディレクトリ名はディレクトリとして扱います。
+func newArticleName(fsys fs.FS, articlesDir, arg string) (string, error) {
+	if arg == "" {
+		dir, err := wp.LatestArticleDir(fsys)
+		if err != nil {
+			return "", err
+		}
+		return wp.NextArticleName(fsys, dir)
+	}
+
+	name, err := articleName(articlesDir, arg)
+	if err != nil {
+		return "", withCode("usage", err)
+	}
+	if strings.HasSuffix(arg, ".md") {
+		return name, nil
+	}
+	if info, err := fs.Stat(fsys, path.Clean(name)); strings.HasSuffix(name, "/") || wp.IsArticleRangeDir(name) || (err == nil && info.IsDir()) {
+		return wp.NextArticleName(fsys, name)
+	}
+	return path.Clean(name), nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"wp/internal/lint"
+	"wp/internal/wp"
+)
+
+// jsonOutput は -output json の場合に true です
+var jsonOutput bool
+
+// stdout は人が読むためのメッセージの出力先です。
+// -output json の場合は標準出力を JSON だけにするため、標準エラー出力に切り替えます。
+var stdout io.Writer = os.Stdout
+
+// setupOutput は -output の値から出力形式を決めます
+func setupOutput(format string) error {
+	switch format {
+	case "text":
+	case "json":
+		jsonOutput = true
+		stdout = os.Stderr
+	default:
+		return usageError("-output には text または json を指定してください: %s", format)
+	}
+	return nil
+}
+
+// 終了コード。diff は diff(1) と同様に、差分あり 1、exitError に当たるエラーは 2 で終了する。
+const (
+	exitOK = 0
+	// exitError は以下に当てはまらないエラーです
+	exitError          = 1
+	exitUsage          = 2
+	exitConfig         = 3
+	exitAuth           = 4
+	exitConflict       = 5
+	exitInvalidArticle = 6
+	exitNotPublished   = 7
+	exitAPI            = 8
+	exitNetwork        = 9
+)
+
+// エラーコード（JSON の error.code）と終了コードの対応
+var exitCodes = map[string]int{
+	"error":           exitError,
+	"usage":           exitUsage,
+	"config":          exitConfig,
+	"auth":            exitAuth,
+	"conflict":        exitConflict,
+	"invalid_article": exitInvalidArticle,
+	"not_published":   exitNotPublished,
+	"api":             exitAPI,
+	"network":         exitNetwork,
+}
+
+// codedError はエラーコードを決めたエラーです。コマンドライン引数や設定の誤りに使います。
+type codedError struct {
+	code string
+	err  error
+}
+
+func (e *codedError) Error() string { return e.err.Error() }
+
+func (e *codedError) Unwrap() error { return e.err }
+
+// usageError はコマンドやフラグの指定の誤りを表すエラーを作成します
+func usageError(format string, args ...interface{}) error {
+	return &codedError{code: "usage", err: fmt.Errorf(format, args...)}
+}
+
+// withCode は err にエラーコードを付けます。err が nil の場合は nil を返します。
+func withCode(code string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &codedError{code: code, err: err}
+}
+
+// errorCode はエラーの種類からエラーコードを決めます。
+// errors.Join でまとめたエラー（sync で複数の記事が失敗した場合）は、最初のエラーのエラーコードにします。
+func errorCode(err error) string {
+	if first := firstJoined(err); first != nil {
+		return errorCode(first)
+	}
+	var coded *codedError
+	var conflict *wp.ConflictError
+	var apiErr *wp.APIError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &coded):
+		return coded.code
+	case errors.As(err, &conflict):
+		return "conflict"
+	case errors.Is(err, wp.ErrAuth):
+		return "auth"
+	case errors.Is(err, wp.ErrInvalidArticle):
+		return "invalid_article"
+	case errors.Is(err, wp.ErrNotPublished):
+		return "not_published"
+	case errors.As(err, &apiErr):
+		if apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden {
+			return "auth"
+		}
+		return "api"
+	case errors.As(err, &urlErr):
+		return "network"
+	}
+	return "error"
+}
+
+// firstJoined は err が包んでいる errors.Join のエラーのうち最初のものを返します。
+// errors.Join のエラーを包んでいない、またはその前にエラーコードを決めたエラーがある場合は nil を返します。
+func firstJoined(err error) error {
+	for err != nil {
+		switch e := err.(type) {
+		case *codedError:
+			return nil
+		case interface{ Unwrap() []error }:
+			if errs := e.Unwrap(); len(errs) > 0 {
+				return errs[0]
+			}
+			return nil
+		}
+		err = errors.Unwrap(err)
+	}
+	return nil
+}
+
+// errorResult は JSON で出力するエラーです
+type errorResult struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// HTTPStatus と APICode は REST API のエラーの場合の HTTP ステータスとエラーコード（rest_post_invalid_id など）です
+	HTTPStatus int    `json:"http_status,omitempty"`
+	APICode    string `json:"api_code,omitempty"`
+}
+
+func newErrorResult(err error) *errorResult {
+	result := &errorResult{Code: errorCode(err), Message: err.Error()}
+	var apiErr *wp.APIError
+	if errors.As(err, &apiErr) {
+		result.HTTPStatus = apiErr.StatusCode
+		result.APICode = apiErr.Code
+	}
+	return result
+}
+
+// articleResult は記事ごとの結果です。コマンドによって使わない項目は出力しません。
+type articleResult struct {
+	Article string `json:"article"`
+	OK      bool   `json:"ok"`
+	// Action は行った操作（create、update、pull、diff、history、rollback、unpublish、trash、delete、promote）です。
+	// sync では読み取れなかった記事が read、ローカルで削除された記事の投稿が残っている場合が orphaned です。
+	Action string `json:"action"`
+	PostID int    `json:"post_id,omitempty"`
+	Link   string `json:"link,omitempty"`
+	// Status は WordPress 上のステータス（publish、draft など）です
+	Status            string           `json:"status,omitempty"`
+	Created           bool             `json:"created,omitempty"`
+	Pulled            bool             `json:"pulled,omitempty"`
+	CreatedCategories []wp.Category    `json:"created_categories,omitempty"`
+	CreatedTags       []wp.Tag         `json:"created_tags,omitempty"`
+	UploadedMedia     []wp.ImageUpload `json:"uploaded_media,omitempty"`
+	Warnings          []string         `json:"warnings,omitempty"`
+	Changes           []wp.FieldChange `json:"changes,omitempty"`
+	// Differs と Diff は diff の結果（unified 形式）です
+	Differs bool   `json:"differs,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	// Revisions は history で取得したリビジョン、RevisionID は rollback で戻したリビジョンです
+	Revisions  []revisionResult `json:"revisions,omitempty"`
+	RevisionID int              `json:"revision_id,omitempty"`
+	Error      *errorResult     `json:"error,omitempty"`
+}
+
+// revisionResult は history で出力するリビジョンです
+type revisionResult struct {
+	ID         int    `json:"id"`
+	Date       string `json:"date"`
+	Author     int    `json:"author"`
+	AuthorName string `json:"author_name"`
+	Title      string `json:"title"`
+	Length     int    `json:"length"`
+}
+
+// failedResult は記事の処理に失敗した場合の結果です
+func failedResult(article, action string, err error) *articleResult {
+	return &articleResult{Article: article, Action: action, Error: newErrorResult(err)}
+}
+
+// output は -output json で標準出力に出力する JSON です
+type output struct {
+	Command  string           `json:"command"`
+	OK       bool             `json:"ok"`
+	ExitCode int              `json:"exit_code"`
+	Results  []*articleResult `json:"results"`
+	// Statuses は status の結果、Diagnostics は lint の結果、Version は version の結果です
+	Statuses    []wp.ArticleStatus `json:"statuses,omitempty"`
+	Diagnostics []lint.Diagnostic  `json:"diagnostics,omitempty"`
+	Version     *versionInfo       `json:"version,omitempty"`
+	Error       *errorResult       `json:"error,omitempty"`
+}
+
+// report はこの実行の結果です
+var report = &output{Results: []*articleResult{}}
+
+// finish は結果を出力し、終了コードで終了します
+func finish(err error) {
+	code := exitOK
+	if err != nil {
+		report.Error = newErrorResult(err)
+		code = exitCodes[report.Error.Code]
+		fmt.Fprintln(stdout, err)
+	}
+	if report.Command == "diff" {
+		if code == exitError {
+			code = 2
+		}
+		for _, r := range report.Results {
+			if r.Differs && code == exitOK {
+				code = exitError
+			}
+		}
+	}
+	report.OK = err == nil
+	report.ExitCode = code
+	if report.Results == nil {
+		report.Results = []*articleResult{}
+	}
+
+	if jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	}
+	os.Exit(code)
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"wp/internal/wp"
+)
+
+func TestErrorCode(t *testing.T) {
+	apiErr := fmt.Errorf("posts/1: %w", &wp.APIError{StatusCode: http.StatusInternalServerError})
+	conflict := fmt.Errorf("posts/2: %w", &wp.ConflictError{Conflict: &wp.Conflict{}})
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"API エラー", apiErr, "api"},
+		{"競合", conflict, "conflict"},
+		{"認証エラーの API エラー", &wp.APIError{StatusCode: http.StatusUnauthorized}, "auth"},
+		{"投稿されていない", fmt.Errorf("エラー: %w", wp.ErrNotPublished), "not_published"},
+		// sync で複数の記事が失敗した場合は、種類によらず最初に失敗した記事のエラーで決める
+		{"最初が API エラー", fmt.Errorf("2 件の記事でエラーが発生しました:\n%w", errors.Join(apiErr, conflict)), "api"},
+		{"最初が競合", fmt.Errorf("2 件の記事でエラーが発生しました:\n%w", errors.Join(conflict, apiErr)), "conflict"},
+		{"エラーコードを付けたエラー", withCode("config", errors.Join(apiErr, conflict)), "config"},
+		{"その他", errors.New("エラー"), "error"},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("%s: errorCode = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
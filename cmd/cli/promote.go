@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	"wp/internal/wp"
+)
+
+// promote は from プロファイルの投稿を現在のプロファイルのサイトにコピーし、コピー先の投稿IDを記事に記録します
+func promote(client *wp.Client, ws *wp.Workspace, config *wp.Config, from, filename string) (*articleResult, error) {
+	srcProfile, err := config.Profile(from)
+	if err != nil {
+		return nil, withCode("config", err)
+	}
+	srcKey := config.StateKey(from)
+	if srcKey == ws.Profile {
+		return nil, usageError("エラー: コピー元とコピー先に同じプロファイルが指定されています: %s", from)
+	}
+
+	metadata, body, err := ws.ReadArticle(filename)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+	src := metadata.StateFor(srcKey)
+	if src.PostID == 0 {
+		return nil, fmt.Errorf("エラー: この記事はプロファイル %s にまだ投稿されていません", from)
+	}
+
+	srcClient, err := newProfileClient(srcProfile)
+	if err != nil {
+		return nil, err
+	}
+	// 本文のほかの記事へのリンクは、コピー元のサイトの URL からコピー先のサイトの URL に置き換える
+	srcWS := *ws
+	srcWS.Profile = srcKey
+	links, err := wp.PromoteLinks(srcClient, &srcWS, client, ws, filename, body)
+	if err != nil {
+		return nil, fmt.Errorf("記事へのリンクのエラー: %w", err)
+	}
+	// 画像のアップロード結果などはコピー先のクライアントのキャッシュに記録される
+	resp, err := wp.PromotePost(srcClient, client, metadata.Type, src.PostID, metadata.PostID, links)
+	if err != nil {
+		return nil, fmt.Errorf("コピーエラー: %w", err)
+	}
+
+	created := metadata.PostID == 0
+	metadata.PostID = resp.ID
+	metadata.Sync = wp.NewSyncState(resp)
+	// コピー先はコピー元に最後に投稿した記事の内容と同じになる
+	if src.Sync != nil {
+		metadata.Sync.LocalHash = src.Sync.LocalHash
+	}
+	if err := wp.UpdateMetadataFS(ws.Articles, filename, metadata); err != nil {
+		return nil, fmt.Errorf("メタデータ更新エラー: %w", err)
+	}
+
+	postType, err := wp.ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.RecordPost(filename, resp.ID, postType.Slug); err != nil {
+		return nil, err
+	}
+
+	if created {
+		fmt.Fprintf(stdout, "投稿ID %d をコピーして作成しました。投稿ID: %d\n", src.PostID, resp.ID)
+	} else {
+		fmt.Fprintf(stdout, "投稿ID %d の内容で投稿ID %d を更新しました\n", src.PostID, resp.ID)
+	}
+	fmt.Fprintf(stdout, "URL: %s\n", resp.Link)
+	return &articleResult{Article: filename, OK: true, Action: "promote", PostID: resp.ID, Link: resp.Link, Status: resp.Status, Created: created}, nil
+}
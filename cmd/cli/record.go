@@ -0,0 +1,36 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"wp/internal/wp"
+)
+
+// transport は -record・-replay を指定した場合にすべてのクライアントが使うトランスポートです
+var transport http.RoundTripper
+
+// replayer は -replay で記録を再生している場合のトランスポートです。再生中は認証情報を使いません。
+var replayer *wp.Replayer
+
+// setupTransport は -record で通信を記録するか、-replay で記録した通信を再生するよう設定します
+func setupTransport(recordDir, replayDir string) error {
+	switch {
+	case recordDir != "" && replayDir != "":
+		return fmt.Errorf("-record と -replay は同時に指定できません")
+	case recordDir != "":
+		recorder, err := wp.NewRecorder(recordDir, http.DefaultTransport)
+		if err != nil {
+			return err
+		}
+		transport = recorder
+	case replayDir != "":
+		r, err := wp.LoadReplayer(replayDir)
+		if err != nil {
+			return err
+		}
+		replayer = r
+		transport = r
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"wp/internal/wp"
+)
+
+// 記事と画像の置き場所の既定値
+const (
+	defaultArticlesDir = "internal/articles"
+	defaultImagesDir   = "internal/images"
+)
+
+// findConfig はカレントディレクトリから親ディレクトリへ順に設定ファイル name を探します。
+// 見つからない場合は空文字を返します。
+func findConfig(name string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		p := filepath.Join(dir, name)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// resolveRoot は記事・画像の置き場所を、フラグ、環境変数、プロファイル、既定値の順で決めます。
+// プロファイルと既定値の相対パスは設定ファイルのディレクトリ（base）からのパスとして扱います。
+// 本文で画像を参照するときの接頭辞として、指定されたままのパスも返します。
+func resolveRoot(flagValue, envName, profileValue, defaultValue, base string) (dir, raw string) {
+	switch {
+	case flagValue != "":
+		return flagValue, flagValue
+	case os.Getenv(envName) != "":
+		return os.Getenv(envName), os.Getenv(envName)
+	case profileValue != "":
+		raw = profileValue
+	default:
+		raw = defaultValue
+	}
+	if filepath.IsAbs(raw) {
+		return raw, raw
+	}
+	return filepath.Join(base, raw), raw
+}
+
+// newWorkspace は記事・画像の置き場所を決めて Workspace を作成し、記事の置き場所のディレクトリとともに返します
+func newWorkspace(articlesFlag, imagesFlag string, profile wp.Profile, base string) (*wp.Workspace, string) {
+	articlesDir, _ := resolveRoot(articlesFlag, "WP_ARTICLES_DIR", profile.ArticlesDir, defaultArticlesDir, base)
+	imagesDir, imagePrefix := resolveRoot(imagesFlag, "WP_IMAGES_DIR", profile.ImagesDir, defaultImagesDir, base)
+
+	ws := wp.NewWorkspace(articlesDir, imagesDir)
+	ws.ImagePrefix = filepath.ToSlash(filepath.Clean(imagePrefix))
+	ws.DefaultStatus = profile.DefaultStatus
+	return ws, articlesDir
+}
+
+// articleName はコマンドラインで指定された記事を記事名（例: posts_001-050/1）にします。
+// .md で終わるファイルのパスが指定された場合は、記事の置き場所からの相対パスにします。
+func articleName(articlesDir, arg string) (string, error) {
+	if !strings.HasSuffix(arg, ".md") {
+		return arg, nil
+	}
+
+	root, err := filepath.Abs(articlesDir)
+	if err != nil {
+		return "", err
+	}
+	file, err := filepath.Abs(arg)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("記事の置き場所（%s）の外にあるファイルです: %s（-articles で置き場所を指定してください）", articlesDir, arg)
+	}
+	return strings.TrimSuffix(filepath.ToSlash(rel), ".md"), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"wp/internal/wp"
+)
+
+// 表示用のローカルの状態
+var localLabels = map[string]string{
+	wp.LocalNew:       "未投稿",
+	wp.LocalUnchanged: "変更なし",
+	wp.LocalModified:  "変更あり",
+	wp.LocalUnknown:   "不明",
+}
+
+// status は記事ごとの同期状態を表形式で表示し、その結果を返します（-output json では JSON の statuses になります）。
+// 引数を省略した場合は internal/articles 以下のすべての記事を対象にします。
+func status(client *wp.Client, ws *wp.Workspace, filenames []string) ([]wp.ArticleStatus, error) {
+	if len(filenames) == 0 {
+		names, err := wp.ListArticlesFS(ws.Articles)
+		if err != nil {
+			return nil, err
+		}
+		filenames = names
+	}
+
+	statuses, err := wp.CollectStatus(client, ws, filenames)
+	if err != nil {
+		return nil, fmt.Errorf("状態取得エラー: %w", err)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ファイル\t投稿ID\tWordPress\tローカル\tWordPress 更新日時\tローカル更新日時")
+	for _, st := range statuses {
+		postID := "-"
+		if st.PostID != 0 {
+			postID = strconv.Itoa(st.PostID)
+		}
+		remote := st.RemoteStatus
+		switch {
+		case remote == "":
+			remote = "-"
+		case remote == wp.RemoteDeleted:
+			remote = "削除済み"
+		case st.RemoteChanged:
+			remote += "（WordPress で変更）"
+		}
+		remoteModified := "-"
+		if st.RemoteModified != "" {
+			remoteModified = strings.Replace(st.RemoteModified, "T", " ", 1) + " UTC"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			st.File, postID, remote, localLabels[st.Local], remoteModified, st.LocalModified.Format("2006-01-02 15:04:05"))
+	}
+	return statuses, w.Flush()
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"wp/internal/wp"
+)
+
+// syncArticles はすべての記事を投稿・更新し、ローカルで削除された記事の投稿を検出します。
+// リンク先の記事を先に投稿するため、ほかの記事へのリンクの順に送信します。最後の投稿から変更のない記事は送信しません。prune が true の場合、ローカルで削除された記事の投稿をゴミ箱に移します。
+// 送信・削除した記事と失敗した記事の結果を返します。
+func syncArticles(client *wp.Client, ws *wp.Workspace, opts publishOptions, prune bool) ([]*articleResult, error) {
+	names, err := wp.ListArticlesFS(ws.Articles)
+	if err != nil {
+		return nil, err
+	}
+	names = wp.SortArticlesByLinks(ws.Articles, names)
+
+	results := []*articleResult{}
+	var errs []error
+	for _, name := range names {
+		metadata, body, err := ws.ReadArticle(name)
+		if err != nil {
+			err = fmt.Errorf("記事読み取りエラー: %w", err)
+			results = append(results, failedResult(name, "read", err))
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+
+		command := "update"
+		switch {
+		case metadata.Status == "trash":
+			continue
+		case metadata.PostID == 0:
+			command = "create"
+		case metadata.Sync != nil && metadata.Sync.LocalHash == wp.HashArticle(metadata, body):
+			continue
+		}
+
+		fmt.Fprintf(stdout, "== %s (%s)\n", name, command)
+		result, err := publish(client, ws, command, name, opts)
+		if err != nil {
+			// 1つの記事の失敗（競合など）で他の記事の同期を止めない
+			fmt.Fprintln(stdout, err)
+			result = failedResult(name, command, err)
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		results = append(results, result)
+	}
+
+	deleted, err := checkDeletedArticles(client, ws, prune)
+	results = append(results, deleted...)
+	if err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return results, fmt.Errorf("%d 件の記事でエラーが発生しました:\n%w", len(errs), errors.Join(errs...))
+	}
+	return results, nil
+}
+
+// checkDeletedArticles はマニフェストに記録されているがファイルが存在しない記事の投稿を検出します。
+// 残っている投稿（orphaned）とゴミ箱に移した投稿（trash）の結果を返します。
+func checkDeletedArticles(client *wp.Client, ws *wp.Workspace, prune bool) ([]*articleResult, error) {
+	manifest, err := ws.LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*articleResult
+	changed := false
+	for name, entry := range manifest.Posts {
+		exists, err := wp.ArticleExists(ws.Articles, name)
+		if err != nil {
+			return results, err
+		}
+		if exists {
+			continue
+		}
+
+		postType, err := wp.ResolvePostType(client, entry.Type)
+		if err != nil {
+			return results, err
+		}
+		post, err := client.GetPostOfType(postType.RestBase, entry.PostID)
+		if err != nil || post.Status == "trash" {
+			// WordPress 上でも削除済み
+			delete(manifest.Posts, name)
+			changed = true
+			continue
+		}
+
+		if !prune {
+			fmt.Fprintf(stdout, "ローカルで削除された記事の投稿が残っています: %s（投稿ID %d、%s）%s\n", name, post.ID, post.Status, post.Link)
+			results = append(results, &articleResult{Article: name, OK: true, Action: "orphaned", PostID: post.ID, Link: post.Link, Status: post.Status})
+			continue
+		}
+		if err := client.DeletePost(postType.RestBase, post.ID, false); err != nil {
+			err = fmt.Errorf("削除エラー: %w", err)
+			results = append(results, failedResult(name, "trash", err))
+			return results, fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(stdout, "ローカルで削除された記事の投稿をゴミ箱に移しました: %s（投稿ID %d）\n", name, post.ID)
+		results = append(results, &articleResult{Article: name, OK: true, Action: "trash", PostID: post.ID, Status: "trash"})
+		delete(manifest.Posts, name)
+		changed = true
+	}
+
+	if changed {
+		return results, manifest.Save()
+	}
+	return results, nil
+}
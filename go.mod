@@ -2,4 +2,7 @@ module wp
 
 go 1.22.0
 
-require github.com/joho/godotenv v1.5.1
+require (
+	github.com/joho/godotenv v1.5.1
+	golang.org/x/crypto v0.33.0
+)
@@ -0,0 +1,259 @@
+// Package lint は記事ファイルを投稿前に検査します。
+package lint
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"wp/internal/wp"
+)
+
+// 検査の種類（Diagnostic.Rule）
+const (
+	RuleParse         = "parse"           // 記事ファイルを読み取れない
+	RuleRequired      = "required"        // Title・Permalink がない
+	RuleMetadata      = "metadata"        // メタデータの値が不正
+	RuleSlug          = "slug"            // スラッグの形式が不正
+	RuleDuplicateSlug = "duplicate-slug"  // 同じ投稿タイプでスラッグが重複している
+	RuleDuplicateID   = "duplicate-id"    // 同じプロファイルで post_id が重複している
+	RuleImage         = "image"           // 画像ファイルがない
+	RuleCodeLanguage  = "code-language"   // コードブロックの言語がハイライトに対応していない
+	RuleCodeBlock     = "code-block"      // コードブロックが閉じられていない
+	RuleHeading       = "heading"         // 見出しのレベルが飛んでいる
+	RuleLink          = "link"            // リンクのテキストまたはリンク先が空、リンク先の記事がない
+	RuleAside         = "aside"           // <aside> と </aside> が対応していない
+	RuleTable         = "table"           // テーブルの列数が揃っていない
+	RuleRule          = "horizontal-rule" // 水平線として扱われない、または意図がはっきりしない ---
+)
+
+// DefaultLanguages はコードブロックに指定できる言語の既定値です。
+// Highlighting Code Block（hcb）に設定した言語に合わせて Linter.Languages で変更します。
+var DefaultLanguages = []string{"bash", "cpp", "dart", "dockerfile", "env", "gitignore", "go", "json", "math", "php", "python", "shell", "yaml"}
+
+// slugPattern は WordPress のスラッグとして使う形式（小文字の英数字をハイフンでつなげたもの）です
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// Diagnostic は記事の問題1つです
+type Diagnostic struct {
+	// File は記事の置き場所からの記事ファイルのパス（例: posts_001-050/1.md）です
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d: %s [%s]", d.File, d.Line, d.Message, d.Rule)
+}
+
+// Linter は記事を検査します
+type Linter struct {
+	Workspace *wp.Workspace
+	// Languages はコードブロックに指定できる言語です。空の場合は DefaultLanguages を使います。
+	Languages []string
+	// Prose は日本語の文章（文体、全角・半角、文の長さ、助詞の重複、用語の表記）も検査する場合に true です
+	Prose bool
+	// Terms は表記を統一する用語（誤った表記 → 正しい表記）です。nil の場合は DefaultTerms を使います。
+	Terms map[string]string
+	// MaxSentenceLength は1文の最大の文字数です。0 の場合は DefaultMaxSentenceLength を使います。
+	MaxSentenceLength int
+}
+
+// New は ws の記事を検査する Linter を作成します
+func New(ws *wp.Workspace) *Linter {
+	return &Linter{Workspace: ws}
+}
+
+// article は検査する記事1つです
+type article struct {
+	name     string
+	file     string
+	header   []string
+	metadata wp.ArticleMetadata
+	body     string
+	// bodyLine は本文の1行目のファイル上の行番号です
+	bodyLine int
+	parsed   bool
+	diags    []Diagnostic
+}
+
+func (a *article) report(line int, rule, format string, args ...interface{}) {
+	a.diags = append(a.diags, Diagnostic{File: a.file, Line: line, Rule: rule, Message: fmt.Sprintf(format, args...)})
+}
+
+// keyLine はメタデータの項目の行番号を返します。項目がない場合は 1 です。
+func (a *article) keyLine(key string) int {
+	quoted := `"` + key + `"`
+	for i, line := range a.header {
+		if strings.HasPrefix(strings.TrimSpace(line), quoted) {
+			return i + 1
+		}
+	}
+	return 1
+}
+
+// Lint は names の記事を検査し、問題をファイルと行の順に返します。names が空の場合はすべての記事を検査します。
+// スラッグと post_id の重複は、指定した記事とすべての記事の間で調べます。
+func (l *Linter) Lint(names []string) ([]Diagnostic, error) {
+	all, err := wp.ListArticlesFS(l.Workspace.Articles)
+	if err != nil {
+		return nil, fmt.Errorf("記事一覧取得エラー: %w", err)
+	}
+	if len(names) == 0 {
+		names = all
+	}
+
+	articles := make(map[string]*article, len(all))
+	load := func(name string) (*article, error) {
+		if a, ok := articles[name]; ok {
+			return a, nil
+		}
+		a, err := l.read(name)
+		if err != nil {
+			return nil, err
+		}
+		articles[name] = a
+		return a, nil
+	}
+	for _, name := range all {
+		if _, err := load(name); err != nil {
+			return nil, err
+		}
+	}
+
+	var diags []Diagnostic
+	for _, name := range names {
+		a, err := load(name)
+		if err != nil {
+			return nil, err
+		}
+		if a.parsed {
+			l.lintMetadata(a)
+			l.lintBody(a)
+			if l.Prose {
+				l.lintProse(a)
+			}
+			lintDuplicates(a, all, articles)
+		}
+		diags = append(diags, a.diags...)
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		if diags[i].File != diags[j].File {
+			return diags[i].File < diags[j].File
+		}
+		return diags[i].Line < diags[j].Line
+	})
+	return diags, nil
+}
+
+// read は記事ファイルを読み込みます。記事として解析できない場合は問題として記録します。
+func (l *Linter) read(name string) (*article, error) {
+	file := name + ".md"
+	content, err := fs.ReadFile(l.Workspace.Articles, file)
+	if err != nil {
+		return nil, fmt.Errorf("ファイル読み取りエラー: %w", err)
+	}
+	a := &article{name: name, file: file}
+	metadata, body, err := wp.ParseArticle(content)
+	if err != nil {
+		a.report(1, RuleParse, "%v", err)
+		return a, nil
+	}
+	headerEnd := len(content) - len(body)
+	a.header = strings.Split(string(content[:headerEnd]), "\n")
+	a.metadata, a.body, a.parsed = metadata, body, true
+	a.bodyLine = bytes.Count(content[:headerEnd], []byte("\n")) + 1
+	return a, nil
+}
+
+func (l *Linter) lintMetadata(a *article) {
+	m := a.metadata
+	if strings.TrimSpace(m.Title) == "" {
+		a.report(a.keyLine("Title"), RuleRequired, "Title がありません")
+	}
+	switch {
+	case m.Permalink == "":
+		a.report(a.keyLine("Permalink"), RuleRequired, "Permalink（スラッグ）がありません")
+	case !slugPattern.MatchString(m.Permalink):
+		a.report(a.keyLine("Permalink"), RuleSlug, "スラッグは小文字の英数字をハイフンでつなげてください: %s", m.Permalink)
+	}
+	if err := m.Validate(); err != nil {
+		for _, msg := range strings.Split(err.Error(), "\n") {
+			a.report(1, RuleMetadata, "%s", msg)
+		}
+	}
+	if m.Image != "" && !isURL(m.Image) {
+		l.checkImage(a, a.keyLine("Image"), m.Image)
+	}
+	if m.SEO != nil && m.SEO.OGImage != "" && !isURL(m.SEO.OGImage) {
+		l.checkImage(a, a.keyLine("OGImage"), m.SEO.OGImage)
+	}
+}
+
+// checkImage は画像の置き場所に画像ファイルがあるかを調べます
+func (l *Linter) checkImage(a *article, line int, name string) {
+	if _, err := fs.Stat(l.Workspace.Images, path.Clean(name)); err != nil {
+		a.report(line, RuleImage, "画像が見つかりません: %s", name)
+	}
+}
+
+func isURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// postType は同じ投稿タイプを同じ名前にします
+func postType(m wp.ArticleMetadata) string {
+	if m.Type == "" || m.Type == "posts" {
+		return "post"
+	}
+	return m.Type
+}
+
+// lintDuplicates は a とほかの記事の間でスラッグと post_id の重複を調べます
+func lintDuplicates(a *article, all []string, articles map[string]*article) {
+	var sameSlug []string
+	sameID := map[string][]string{}
+	for _, name := range all {
+		other := articles[name]
+		if name == a.name || !other.parsed {
+			continue
+		}
+		if a.metadata.Permalink != "" && other.metadata.Permalink == a.metadata.Permalink && postType(other.metadata) == postType(a.metadata) {
+			sameSlug = append(sameSlug, other.file)
+		}
+		for _, profile := range profiles(a.metadata) {
+			id := a.metadata.StateFor(profile).PostID
+			if id != 0 && other.metadata.StateFor(profile).PostID == id {
+				sameID[profile] = append(sameID[profile], other.file)
+			}
+		}
+	}
+	if len(sameSlug) > 0 {
+		a.report(a.keyLine("Permalink"), RuleDuplicateSlug, "スラッグ %s がほかの記事と重複しています: %s", a.metadata.Permalink, strings.Join(sameSlug, ", "))
+	}
+	for _, profile := range profiles(a.metadata) {
+		if files := sameID[profile]; len(files) > 0 {
+			key, label := "post_id", ""
+			if profile != "" {
+				key, label = profile, "（プロファイル "+profile+"）"
+			}
+			a.report(a.keyLine(key), RuleDuplicateID, "投稿ID %d%s がほかの記事と重複しています。update で別の記事の投稿を上書きします: %s",
+				a.metadata.StateFor(profile).PostID, label, strings.Join(files, ", "))
+		}
+	}
+}
+
+// profiles は記事に投稿IDが記録されているプロファイルを返します。既定のプロファイルは空の名前です。
+func profiles(m wp.ArticleMetadata) []string {
+	names := []string{""}
+	for name := range m.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
@@ -0,0 +1,109 @@
+package lint_test
+
+import (
+	"reflect"
+	"testing"
+
+	"wp/internal/lint"
+	"wp/internal/wp/wptest"
+)
+
+// header は記事のメタデータです。本文の1行目はファイルの6行目になります。
+const header = "{\n  \"Title\": \"記事\",\n  \"Permalink\": \"article\"\n}\n---\n"
+
+// withBody は本文が body の記事 a.md だけの置き場所の記事です
+func withBody(body string) map[string]string {
+	return map[string]string{"a.md": header + body}
+}
+
+// lintLines は files の記事を検査し、rule の問題の行番号をファイルと行の順に返します
+func lintLines(t *testing.T, linter *lint.Linter, files map[string]string, rule string) []int {
+	t.Helper()
+	linter.Workspace = wptest.NewWorkspace(files, map[string]string{"y.png": "\x89PNG image data"})
+	diags, err := linter.Lint(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, d := range diags {
+		if d.Rule == rule {
+			lines = append(lines, d.Line)
+		}
+	}
+	return lines
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		files map[string]string
+		want  []int
+	}{
+		{"区切りがある", lint.RuleParse, withBody("本文\n"), nil},
+		{"区切りがない", lint.RuleParse, map[string]string{"a.md": `{"Title": "記事"}`}, []int{1}},
+
+		{"Title と Permalink がある", lint.RuleRequired, withBody("本文\n"), nil},
+		{"Title と Permalink が空", lint.RuleRequired, map[string]string{"a.md": "{\n  \"Title\": \"\",\n  \"Permalink\": \"\"\n}\n---\n本文\n"}, []int{2, 3}},
+
+		{"Status が正しい", lint.RuleMetadata, map[string]string{"a.md": "{\n  \"Title\": \"記事\",\n  \"Permalink\": \"article\",\n  \"Status\": \"draft\"\n}\n---\n"}, nil},
+		{"Status が不正", lint.RuleMetadata, map[string]string{"a.md": "{\n  \"Title\": \"記事\",\n  \"Permalink\": \"article\",\n  \"Status\": \"published\"\n}\n---\n"}, []int{1}},
+
+		{"スラッグが小文字とハイフン", lint.RuleSlug, withBody(""), nil},
+		{"スラッグに大文字と下線", lint.RuleSlug, map[string]string{"a.md": "{\n  \"Title\": \"記事\",\n  \"Permalink\": \"Hello_World\"\n}\n---\n"}, []int{3}},
+
+		{"投稿タイプが異なる同じスラッグ", lint.RuleDuplicateSlug, map[string]string{
+			"a.md": header,
+			"b.md": "{\n  \"Title\": \"ページ\",\n  \"Permalink\": \"article\",\n  \"Type\": \"page\"\n}\n---\n",
+		}, nil},
+		{"同じ投稿タイプの同じスラッグ", lint.RuleDuplicateSlug, map[string]string{"a.md": header, "b.md": header}, []int{3, 3}},
+
+		{"post_id が異なる", lint.RuleDuplicateID, map[string]string{
+			"a.md": "{\n  \"Title\": \"a\",\n  \"Permalink\": \"a\",\n  \"post_id\": 10\n}\n---\n",
+			"b.md": "{\n  \"Title\": \"b\",\n  \"Permalink\": \"b\",\n  \"post_id\": 11\n}\n---\n",
+		}, nil},
+		{"post_id が同じ", lint.RuleDuplicateID, map[string]string{
+			"a.md": "{\n  \"Title\": \"a\",\n  \"Permalink\": \"a\",\n  \"post_id\": 10\n}\n---\n",
+			"b.md": "{\n  \"Title\": \"b\",\n  \"Permalink\": \"b\",\n  \"post_id\": 10\n}\n---\n",
+		}, []int{4, 4}},
+
+		{"画像がある", lint.RuleImage, withBody("![図](images/y.png)\n![図](https://example.com/z.png)\n"), nil},
+		{"画像がない・置き場所の外・パスが空", lint.RuleImage, withBody("![図](images/z.png)\n![図](other/y.png)\n![図]()\n"), []int{6, 7, 8}},
+
+		{"対応している言語", lint.RuleCodeLanguage, withBody("```go\nfunc main() {}\n```\n"), nil},
+		{"対応していない言語", lint.RuleCodeLanguage, withBody("```rust\nfn main() {}\n```\n"), []int{6}},
+
+		{"閉じたコードブロック", lint.RuleCodeBlock, withBody("```go\n```\n"), nil},
+		{"閉じていないコードブロック", lint.RuleCodeBlock, withBody("本文\n```go\nfunc main() {}\n"), []int{7}},
+
+		{"順に深くなる見出し", lint.RuleHeading, withBody("## a\n### b\n#### c\n## d\n"), nil},
+		{"飛んだ見出し・深すぎる見出し", lint.RuleHeading, withBody("## a\n#### b\n##### c\n"), []int{7, 8}},
+		{"コードブロックの中の # は見出しではない", lint.RuleHeading, withBody("```bash\n#### comment\n```\n"), nil},
+
+		{"テキストとリンク先がある", lint.RuleLink, map[string]string{"a.md": header + "[b](b.md)\n[例](https://example.com)\n`[](x)`\n", "b.md": header}, nil},
+		{"テキストが空・リンク先が空・記事がない", lint.RuleLink, withBody("[](https://example.com)\n[a]()\n[[c]]\n"), []int{6, 7, 8}},
+
+		{"対応した aside", lint.RuleAside, withBody("<aside>\n注意\n</aside>\n"), nil},
+		{"閉じていない aside・対応のない /aside", lint.RuleAside, withBody("</aside>\n<aside>\n注意\n"), []int{6, 7}},
+
+		{"揃ったテーブル", lint.RuleTable, withBody("| a | b |\n|---|---|\n| 1 | 2 |\n"), nil},
+		{"列数が異なるテーブル", lint.RuleTable, withBody("| a | b |\n|---|---|\n| 1 |\n"), []int{8}},
+		{"区切りのないテーブル", lint.RuleTable, withBody("| a | b |\n| 1 | 2 |\n"), []int{6}},
+
+		{"前後に空行のある水平線", lint.RuleRule, withBody("本文\n\n---\n\n続き\n"), nil},
+		{"段落に続く・空白のある・末尾の ---", lint.RuleRule, withBody("本文\n---\n\n ---\n\n---\n"), []int{7, 9, 11}},
+	}
+	for _, tt := range tests {
+		if got := lintLines(t, &lint.Linter{}, tt.files, tt.rule); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %s lines = %v, want %v", tt.name, tt.rule, got, tt.want)
+		}
+	}
+}
+
+// Languages を指定するとハイライトに設定した言語だけを使える
+func TestLintLanguages(t *testing.T) {
+	files := withBody("```rust\nfn main() {}\n```\n")
+	if got := lintLines(t, &lint.Linter{Languages: []string{"rust"}}, files, lint.RuleCodeLanguage); got != nil {
+		t.Errorf("lines = %v, want none", got)
+	}
+}
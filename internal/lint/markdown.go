@@ -0,0 +1,202 @@
+package lint
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"wp/internal/wp"
+)
+
+var (
+	headingPattern     = regexp.MustCompile(`^(#+)\s`)
+	linkPattern        = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)]*)\)`)
+	asideTag           = regexp.MustCompile(`</?aside>`)
+	tableSeparator     = regexp.MustCompile(`^\|(\s*:?-+:?\s*\|)+$`)
+	horizontalRuleLike = regexp.MustCompile(`^\s*-{3,}\s*$`)
+)
+
+// maxHeadingLevel は本文の変換で見出しになる最も深いレベル（####）です
+const maxHeadingLevel = 4
+
+// lintBody は本文のマークダウンを、WordPress に投稿するときの HTML への変換と同じ規則で検査します
+func (l *Linter) lintBody(a *article) {
+	languages := l.Languages
+	if len(languages) == 0 {
+		languages = DefaultLanguages
+	}
+
+	lines := strings.Split(a.body, "\n")
+	// コードを除いた本文。コードブロックの言語と閉じ忘れだけは元の行で調べる
+	masked := strings.Split(wp.MaskCode(a.body), "\n")
+	// 記事のタイトルを h1 として、本文の見出しは h2 から始める
+	prevHeading := 1
+	fenceLine := 0
+	var asides []int
+	var table []int
+
+	for i, line := range lines {
+		n := a.bodyLine + i
+		trimmed := strings.TrimSpace(line)
+
+		if strings.HasPrefix(trimmed, "```") {
+			if fenceLine == 0 {
+				fenceLine = n
+				if lang := strings.TrimSpace(trimmed[3:]); lang != "" && !contains(languages, lang) {
+					a.report(n, RuleCodeLanguage, "コードブロックの言語 %s はハイライトに対応していません（%s）", lang, strings.Join(languages, ", "))
+				}
+			} else {
+				fenceLine = 0
+			}
+			continue
+		}
+		if fenceLine != 0 {
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, "|") && strings.HasSuffix(trimmed, "|") {
+			table = append(table, i)
+			continue
+		}
+		if len(table) > 0 {
+			lintTable(a, lines, table)
+			table = nil
+		}
+
+		text := masked[i]
+
+		if m := headingPattern.FindStringSubmatch(text); m != nil {
+			level := len(m[1])
+			switch {
+			case level > maxHeadingLevel:
+				a.report(n, RuleHeading, "見出しは #### までです（%s は見出しになりません）", m[1])
+			case level > prevHeading+1:
+				a.report(n, RuleHeading, "見出しのレベルが h%d から h%d に飛んでいます", prevHeading, level)
+			}
+			prevHeading = min(level, maxHeadingLevel)
+		}
+
+		for _, m := range linkPattern.FindAllStringSubmatch(text, -1) {
+			image, label, target := m[1] == "!", strings.TrimSpace(m[2]), strings.TrimSpace(m[3])
+			switch {
+			case target == "" && image:
+				a.report(n, RuleImage, "画像のパスが空です")
+			case target == "":
+				a.report(n, RuleLink, "リンク先が空です: %s", m[0])
+			case image:
+				l.lintInlineImage(a, n, target)
+			case label == "":
+				a.report(n, RuleLink, "リンクのテキストが空です: %s", m[0])
+			}
+		}
+
+		l.lintArticleLinks(a, n, text)
+
+		for _, tag := range asideTag.FindAllString(text, -1) {
+			switch {
+			case tag == "<aside>" && len(asides) > 0:
+				a.report(n, RuleAside, "<aside> の中に <aside> があります（%d 行目の <aside> が閉じられていません）", asides[len(asides)-1])
+				asides = append(asides, n)
+			case tag == "<aside>":
+				asides = append(asides, n)
+			case len(asides) == 0:
+				a.report(n, RuleAside, "対応する <aside> のない </aside> です")
+			default:
+				asides = asides[:len(asides)-1]
+			}
+		}
+
+		lintHorizontalRule(a, lines, i)
+	}
+
+	if len(table) > 0 {
+		lintTable(a, lines, table)
+	}
+	if fenceLine != 0 {
+		a.report(fenceLine, RuleCodeBlock, "コードブロックが ``` で閉じられていません")
+	}
+	for _, n := range asides {
+		a.report(n, RuleAside, "<aside> が </aside> で閉じられていません")
+	}
+}
+
+// lintInlineImage は本文で参照している画像を調べます。画像の置き場所以下のパスだけがアップロードされます。
+func (l *Linter) lintInlineImage(a *article, n int, target string) {
+	if isURL(target) {
+		return
+	}
+	prefix := l.Workspace.ImagePrefix + "/"
+	if !strings.HasPrefix(target, prefix) {
+		a.report(n, RuleImage, "画像が画像の置き場所（%s）以下にないため、アップロードされません: %s", l.Workspace.ImagePrefix, target)
+		return
+	}
+	l.checkImage(a, n, path.Clean(strings.TrimPrefix(target, prefix)))
+}
+
+// lintArticleLinks は行のほかの記事へのリンクのリンク先の記事があるかを調べます
+func (l *Linter) lintArticleLinks(a *article, n int, text string) {
+	links, err := wp.FindArticleLinks(a.name, text)
+	if err != nil {
+		a.report(n, RuleLink, "%v", err)
+		return
+	}
+	for _, link := range links {
+		if ok, err := wp.ArticleExists(l.Workspace.Articles, link.Target); err == nil && !ok {
+			a.report(n, RuleLink, "リンク先の記事が見つかりません: %s", link.Target)
+		}
+	}
+}
+
+// lintTable はテーブルの行（lines の添字）を調べます。本文の変換では、ヘッダー・区切り・本文の3行以上ある場合だけテーブルにします。
+func lintTable(a *article, lines []string, rows []int) {
+	first := a.bodyLine + rows[0]
+	if len(rows) < 3 {
+		a.report(first, RuleTable, "テーブルにはヘッダー・区切り（|---|）・本文の行が必要です。テーブルに変換されません")
+		return
+	}
+	columns := len(tableCells(lines[rows[0]]))
+	if separator := strings.ReplaceAll(strings.TrimSpace(lines[rows[1]]), " ", ""); !tableSeparator.MatchString(separator) {
+		a.report(a.bodyLine+rows[1], RuleTable, "テーブルの2行目は |---|---| のような区切りの行にしてください")
+	}
+	for _, row := range rows[1:] {
+		if got := len(tableCells(lines[row])); got != columns {
+			a.report(a.bodyLine+row, RuleTable, "テーブルの列数が %d ですが、ヘッダーは %d 列です", got, columns)
+		}
+	}
+}
+
+func tableCells(row string) []string {
+	return strings.Split(strings.Trim(strings.TrimSpace(row), "|"), "|")
+}
+
+// lintHorizontalRule は lines[i] が --- のような行の場合に、意図どおり水平線になるかを調べます。
+// 本文の変換では、改行で終わる "---" だけの行を水平線にします。
+func lintHorizontalRule(a *article, lines []string, i int) {
+	line := lines[i]
+	if !horizontalRuleLike.MatchString(line) {
+		return
+	}
+	n := a.bodyLine + i
+	if line != "---" {
+		a.report(n, RuleRule, "水平線は前後に空白のない --- だけの行にしてください。%q はそのまま表示されます", line)
+		return
+	}
+	rest := strings.TrimSpace(strings.Join(lines[i+1:], "\n"))
+	switch {
+	case i == len(lines)-1:
+		a.report(n, RuleRule, "末尾の --- の後に改行がないため、水平線にならずそのまま表示されます")
+	case rest == "":
+		a.report(n, RuleRule, "記事の末尾の --- は水平線として表示されます。メタデータとの区切りのつもりであれば削除してください")
+	case i > 0 && strings.TrimSpace(lines[i-1]) != "":
+		a.report(n, RuleRule, "直前の行に続く --- は、一般的なマークダウンでは見出しになります。水平線の場合は前に空行を入れてください")
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
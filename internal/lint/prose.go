@@ -0,0 +1,252 @@
+package lint
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"wp/internal/wp"
+)
+
+// 日本語の文章の検査の種類（Linter.Prose が true の場合）
+const (
+	RuleStyle           = "style"            // ですます調とである調が混在している
+	RuleWidth           = "width"            // 全角の英数字、半角カタカナ、半角の句読点
+	RuleSentenceLength  = "sentence-length"  // 1文が長すぎる
+	RuleDoubledParticle = "doubled-particle" // 読点までの間に同じ助詞が2回以上ある
+	RuleTerm            = "term"             // 表記を統一する用語
+)
+
+// DefaultMaxSentenceLength は1文の最大の文字数の既定値です
+const DefaultMaxSentenceLength = 100
+
+// DefaultTerms は表記を統一する用語（誤った表記 → 正しい表記）の既定値です
+var DefaultTerms = map[string]string{
+	"Wordpress":  "WordPress",
+	"Javascript": "JavaScript",
+	"Typescript": "TypeScript",
+	"Github":     "GitHub",
+	"Gitlab":     "GitLab",
+	"Pytorch":    "PyTorch",
+	"Atcoder":    "AtCoder",
+	"Mysql":      "MySQL",
+	"Postgresql": "PostgreSQL",
+	"Youtube":    "YouTube",
+}
+
+var (
+	reProseImage     = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+	reProseLink      = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	reProseURL       = regexp.MustCompile(`https?://\S+`)
+	reProseTag       = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	reProseMarker    = regexp.MustCompile(`^\s*(#+|[-*+]|\d+\.|>)\s+`)
+	reFullWidthAlnum = regexp.MustCompile(`[Ａ-Ｚａ-ｚ０-９]+`)
+	reHalfKana       = regexp.MustCompile(`[｡-ﾟ]+`)
+	reHalfPunct      = regexp.MustCompile(`[\p{Hiragana}\p{Katakana}\p{Han}]([,.!?])`)
+	reDesuMasu       = regexp.MustCompile(`(です|ます|でした|ました|ません|ませんでした|ましょう|でしょう|ください)[よねか]?$`)
+	reDearu          = regexp.MustCompile(`(である|であった|であろう|だ|だった|だろう)[よねか]?$`)
+)
+
+// sentenceEnd は文の終わりの文字です
+const sentenceEnd = "。！？!?"
+
+// particles は読点までの間に2回以上使うと読みにくい助詞です。「AをしてBを」のように動詞ごとに使う「を」は数えません。
+var particles = []rune{'は', 'が'}
+
+// proseLine は検査する文章の1行です
+type proseLine struct {
+	line int
+	text string
+	// sentences は文体と文の長さを調べる行の場合に true です（見出しとテーブルは文ではない）
+	sentences bool
+}
+
+// lintProse は本文（コードを除く）とタイトルの日本語の文章を検査します
+func (l *Linter) lintProse(a *article) {
+	var lines []proseLine
+	lines = append(lines, proseLine{line: a.keyLine("Title"), text: a.metadata.Title})
+	for i, text := range strings.Split(wp.MaskCode(a.body), "\n") {
+		trimmed := strings.TrimSpace(text)
+		if tableSeparator.MatchString(strings.ReplaceAll(trimmed, " ", "")) {
+			continue
+		}
+		isTable := strings.HasPrefix(trimmed, "|") && strings.HasSuffix(trimmed, "|")
+		isHeading := strings.HasPrefix(trimmed, "#")
+		text = reProseImage.ReplaceAllString(text, "")
+		text = reProseLink.ReplaceAllString(text, "$1")
+		text = reProseURL.ReplaceAllString(text, "")
+		text = reProseTag.ReplaceAllString(text, "")
+		text = reProseMarker.ReplaceAllString(text, "")
+		text = strings.ReplaceAll(text, "**", "")
+		if isTable {
+			// セルごとに文として区切る
+			text = strings.ReplaceAll(strings.Trim(trimmed, "|"), "|", "。")
+		}
+		lines = append(lines, proseLine{line: a.bodyLine + i, text: text, sentences: !isTable && !isHeading})
+	}
+
+	terms := l.Terms
+	if terms == nil {
+		terms = DefaultTerms
+	}
+	maxLength := l.MaxSentenceLength
+	if maxLength == 0 {
+		maxLength = DefaultMaxSentenceLength
+	}
+
+	type styled struct {
+		line  int
+		dearu bool
+	}
+	var styles []styled
+	for i, pl := range lines {
+		lintWidth(a, pl)
+		lintTerms(a, pl, terms)
+		if i == 0 {
+			// タイトルは文として扱わない
+			continue
+		}
+		for _, sentence := range splitSentences(pl.text) {
+			lintDoubledParticles(a, pl.line, sentence)
+			if !pl.sentences {
+				continue
+			}
+			if n := utf8.RuneCountInString(sentence); n > maxLength {
+				a.report(pl.line, RuleSentenceLength, "1文が %d 文字あります（%d 文字以下にしてください）: %s", n, maxLength, excerpt(sentence))
+			}
+			body := strings.TrimRight(sentence, sentenceEnd+"）)」』")
+			switch {
+			case reDesuMasu.MatchString(body):
+				styles = append(styles, styled{line: pl.line})
+			case reDearu.MatchString(body):
+				styles = append(styles, styled{line: pl.line, dearu: true})
+			}
+		}
+	}
+
+	// 多いほうの文体に合わせる。同数の場合はですます調にする
+	var dearu int
+	for _, s := range styles {
+		if s.dearu {
+			dearu++
+		}
+	}
+	desumasu := len(styles) - dearu
+	if dearu == 0 || desumasu == 0 {
+		return
+	}
+	minority, name := true, "である調"
+	if dearu > desumasu {
+		minority, name = false, "ですます調"
+	}
+	for _, s := range styles {
+		if s.dearu == minority {
+			a.report(s.line, RuleStyle, "%s の文があります。文体をそろえてください（ですます調 %d 文、である調 %d 文）", name, desumasu, dearu)
+		}
+	}
+}
+
+// splitSentences は行を文に分けます。文の終わりの文字は文に含めます。
+// 「**用途**: 説明」「用途：説明」のような項目名の後のコロンでも区切り、項目名を説明の文に含めません。
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	for i, r := range text {
+		label := r == '：' || r == ':' && (i+1 == len(text) || text[i+1] == ' ')
+		if strings.ContainsRune(sentenceEnd, r) || label {
+			end := i + utf8.RuneLen(r)
+			sentences = appendSentence(sentences, text[start:end])
+			start = end
+		}
+	}
+	return appendSentence(sentences, text[start:])
+}
+
+// appendSentence は空白（コードを除いた跡を含む）をまとめて文を追加します
+func appendSentence(sentences []string, s string) []string {
+	if s = strings.Join(strings.Fields(s), " "); s == "" {
+		return sentences
+	}
+	return append(sentences, s)
+}
+
+// excerpt は長い文の先頭を返します
+func excerpt(s string) string {
+	const n = 20
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
+
+func lintWidth(a *article, pl proseLine) {
+	for _, m := range reFullWidthAlnum.FindAllString(pl.text, -1) {
+		a.report(pl.line, RuleWidth, "全角の英数字は半角にしてください: %s", m)
+	}
+	for _, m := range reHalfKana.FindAllString(pl.text, -1) {
+		a.report(pl.line, RuleWidth, "半角カタカナは全角にしてください: %s", m)
+	}
+	for _, m := range reHalfPunct.FindAllStringSubmatch(pl.text, -1) {
+		want := map[string]string{",": "、", ".": "。", "!": "！", "?": "？"}[m[1]]
+		a.report(pl.line, RuleWidth, "日本語の文では %q ではなく「%s」を使ってください: %s", m[1], want, m[0])
+	}
+}
+
+// lintTerms は表記を統一する用語を調べます。英数字の単語の一部には一致させません。
+func lintTerms(a *article, pl proseLine, terms map[string]string) {
+	wrongs := make([]string, 0, len(terms))
+	for wrong := range terms {
+		wrongs = append(wrongs, wrong)
+	}
+	sort.Strings(wrongs)
+	for _, wrong := range wrongs {
+		for text := pl.text; ; {
+			i := strings.Index(text, wrong)
+			if i < 0 {
+				break
+			}
+			before, _ := utf8.DecodeLastRuneInString(text[:i])
+			after, _ := utf8.DecodeRuneInString(text[i+len(wrong):])
+			if !isWordRune(before) && !isWordRune(after) {
+				a.report(pl.line, RuleTerm, "%s ではなく %s と書いてください", wrong, terms[wrong])
+				break
+			}
+			text = text[i+len(wrong):]
+		}
+	}
+}
+
+func isWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// lintDoubledParticles は文の読点で区切った部分ごとに、同じ助詞が2回以上あるかを調べます。
+// 形態素解析の代わりに、漢字・カタカナ・英数字・括弧の直後の助詞だけを数えます（「これは」「には」などは数えません）。
+func lintDoubledParticles(a *article, line int, sentence string) {
+	for _, clause := range strings.FieldsFunc(sentence, func(r rune) bool { return r == '、' || r == '，' }) {
+		counts := map[rune]int{}
+		prev := rune(0)
+		for _, r := range clause {
+			if containsRune(particles, r) && prev != 0 && !unicode.In(prev, unicode.Hiragana) && (unicode.IsLetter(prev) || unicode.IsDigit(prev) || strings.ContainsRune("」』）)", prev)) {
+				counts[r]++
+			}
+			prev = r
+		}
+		for _, p := range particles {
+			if counts[p] >= 2 {
+				a.report(line, RuleDoubledParticle, "読点までの間に助詞「%c」が %d 回あります: %s", p, counts[p], excerpt(strings.TrimSpace(clause)))
+			}
+		}
+	}
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, v := range runes {
+		if v == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package lint_test
+
+import (
+	"reflect"
+	"testing"
+
+	"wp/internal/lint"
+)
+
+func TestLintProse(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		body string
+		want []int
+	}{
+		{"ですます調だけ", lint.RuleStyle, "これはペンです。\nあれは本です。\n", nil},
+		{"少ないほうの文体", lint.RuleStyle, "これはペンです。\nあれは本です。\nそれは机である。\n", []int{8}},
+		{"コードの中の文体", lint.RuleStyle, "これはペンです。\n`それは机である。`\n```\nそれは机である。\n```\n", nil},
+
+		{"半角の英数字と全角の句読点", lint.RuleWidth, "バージョン 3 です。Go 1.22 と config.yaml\n", nil},
+		{"全角英数字・半角カタカナ・半角の句読点", lint.RuleWidth, "バージョン３です。\nｶﾀｶﾅです。\n日本語です.\n", []int{6, 7, 8}},
+		{"コードの中の全角英数字と半角カタカナ", lint.RuleWidth, "`ＡＢＣ` です。\n```\nｶﾀｶﾅ\n```\n", nil},
+
+		{"短い文", lint.RuleSentenceLength, "短い文です。\n", nil},
+		{"長い文", lint.RuleSentenceLength, "この文は十五文字を大きく超えています。\n", []int{6}},
+		{"コードの中の長い文", lint.RuleSentenceLength, "`この文は十五文字を大きく超えています`。\n```\nこの文は十五文字を大きく超えています。\n```\n", nil},
+		{"項目名と説明は別の文", lint.RuleSentenceLength, "- **とても長い項目名の見出し**: 短い説明です。\n- 項目名：説明です。\n", nil},
+
+		{"助詞が1回ずつ", lint.RuleDoubledParticle, "これは私が書いた記事です。\n記事が長く、読者が疲れる。\n", nil},
+		{"読点までに同じ助詞が2回", lint.RuleDoubledParticle, "記事が長い場合が多い。\n", []int{6}},
+		{"項目名と説明の助詞", lint.RuleDoubledParticle, "- **記事が長い**: 読者が疲れる。\n", nil},
+		{"コードの中の助詞", lint.RuleDoubledParticle, "`値が大きい場合が`\n```\n値が大きい場合が多い。\n```\n", nil},
+
+		{"正しい表記", lint.RuleTerm, "WordPress と GitHub\n", nil},
+		{"誤った表記", lint.RuleTerm, "Wordpress と Github\n", []int{6, 6}},
+		{"単語の一部・コード・URL", lint.RuleTerm, "MyWordpressPlugin\n`Wordpress`\n[WordPress](https://example.com/Wordpress)\n```\nGithub\n```\n", nil},
+	}
+	for _, tt := range tests {
+		linter := &lint.Linter{Prose: true, MaxSentenceLength: 15}
+		if got := lintLines(t, linter, withBody(tt.body), tt.rule); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: %s lines = %v, want %v", tt.name, tt.rule, got, tt.want)
+		}
+	}
+}
+
+// Terms を指定すると既定の用語の代わりに使う
+func TestLintProseTerms(t *testing.T) {
+	linter := &lint.Linter{Prose: true, Terms: map[string]string{"ウェブ": "Web"}}
+	if got := lintLines(t, linter, withBody("ウェブと Wordpress\n"), lint.RuleTerm); !reflect.DeepEqual(got, []int{6}) {
+		t.Errorf("lines = %v, want [6]", got)
+	}
+}
+
+// Prose が false の場合は文章を検査しない
+func TestLintWithoutProse(t *testing.T) {
+	for _, rule := range []string{lint.RuleStyle, lint.RuleWidth, lint.RuleSentenceLength, lint.RuleDoubledParticle, lint.RuleTerm} {
+		if got := lintLines(t, &lint.Linter{}, withBody("Wordpress３が長い場合が多いである。\nです。\n"), rule); got != nil {
+			t.Errorf("%s lines = %v, want none", rule, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package wp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// API は記事の投稿に使う WordPress の REST API（投稿・ターム・メディア・ユーザー）です。
+// Client が実装します。テストでは wptest.Fake のメモリ上の実装に置き換えられます。
+type API interface {
+	// PostTypes はサイトに登録されている投稿タイプをスラッグをキーにして返します
+	PostTypes() (map[string]PostType, error)
+	// PostSchema は投稿タイプのスキーマ（OPTIONS /wp/v2/{rest_base}）を返します
+	PostSchema(restBase string) (*PostSchema, error)
+
+	GetPostOfType(restBase string, postID int) (*PostResponse, error)
+	// ListPosts は条件に合う投稿をすべてのページにわたって取得します（context=edit）
+	ListPosts(restBase string, query PostQuery) ([]PostResponse, error)
+	CreatePostOfType(restBase string, post PostRequest) (*PostResponse, error)
+	UpdatePostOfType(restBase string, postID int, post PostRequest) (*PostResponse, error)
+	UpdatePostFields(restBase string, postID int, fields map[string]interface{}) (*PostResponse, error)
+	DeletePost(restBase string, postID int, force bool) error
+	// Revisions は投稿のリビジョンを新しい順に返します
+	Revisions(restBase string, postID int) ([]Revision, error)
+	Revision(restBase string, postID, revisionID int) (*Revision, error)
+
+	Categories() ([]Category, error)
+	// DefaultCategory はカテゴリーを指定しない投稿に設定される既定のカテゴリーのIDです
+	DefaultCategory() (int, error)
+	CreateCategory(name string) (*Category, error)
+	Tags() ([]Tag, error)
+	CreateTag(name string) (*Tag, error)
+
+	// UploadMedia はファイル名 name で画像をアップロードします
+	UploadMedia(name string, data []byte) (*MediaResponse, error)
+	GetMedia(id int) (*MediaResponse, error)
+	// SearchMedia はメディアライブラリの画像をタイトル（アップロード時のファイル名）で検索します
+	SearchMedia(search string) ([]MediaResponse, error)
+	// DownloadMedia はメディアのファイルを取得します
+	DownloadMedia(media MediaResponse) ([]byte, error)
+
+	// SearchUsers はユーザー名・スラッグ・表示名で検索します（context=edit）
+	SearchUsers(search string) ([]User, error)
+	GetUser(id int) (*User, error)
+	// CurrentUser は認証したユーザーを返します
+	CurrentUser() (*User, error)
+
+	// SharedCache はアップロードしたメディアや検索したユーザーを記録するキャッシュです。nil の場合は記録しません。
+	SharedCache() *Cache
+}
+
+var _ API = (*Client)(nil)
+
+// APIError は REST API のエラーレスポンス（{"code": ..., "message": ..., "data": {"status": ...}}）です
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// TermID は term_exists の場合の既存のタームのIDです
+	TermID int
+}
+
+func (e *APIError) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("APIエラー: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("APIエラー: %s - %s", e.Code, e.Message)
+}
+
+// parseAPIError はエラーレスポンスのボディを APIError にします。JSON でない場合は Code が空です。
+func parseAPIError(statusCode int, data []byte) *APIError {
+	var body struct {
+		Code    string          `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(data, &body); err != nil {
+		return &APIError{StatusCode: statusCode}
+	}
+	var extra struct {
+		TermID int `json:"term_id"`
+	}
+	json.Unmarshal(body.Data, &extra)
+	return &APIError{StatusCode: statusCode, Code: body.Code, Message: body.Message, TermID: extra.TermID}
+}
+
+// PostQuery は ListPosts で取得する投稿の条件です
+type PostQuery struct {
+	// Include は取得する投稿のIDです。空の場合はIDで絞り込みません。
+	Include []int
+	Slug    string
+	// Status は取得する投稿のステータスです。空の場合は公開済み（publish）の投稿だけです。
+	Status []string
+	// Fields は取得するフィールド（_fields）です。空の場合はすべてのフィールドを取得します。
+	Fields []string
+}
+
+// withContext は api が Client の場合に、リクエストを ctx で中断できるようにします。
+// メモリ上の実装など、それ以外の API はそのまま返します。
+func withContext(api API, ctx context.Context) API {
+	if c, ok := api.(*Client); ok {
+		return c.WithContext(ctx)
+	}
+	return api
+}
@@ -0,0 +1,379 @@
+package wp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// 認証方式の名前（WP_AUTH などの設定で指定する値）
+const (
+	AuthBasic               = "basic"
+	AuthApplicationPassword = "application-password"
+	AuthJWT                 = "jwt"
+	AuthCookie              = "cookie"
+	AuthBearer              = "bearer"
+)
+
+// Authenticator はリクエストに認証情報を設定します
+type Authenticator interface {
+	Authenticate(c *Client, req *http.Request) error
+}
+
+// Refresher は認証エラーのレスポンスを受けて認証情報を取り直せる Authenticator です。
+// 取り直した場合は true を返し、リクエストが一度だけ再送されます。
+type Refresher interface {
+	Refresh(c *Client, statusCode int, code string) (bool, error)
+}
+
+// Credentials は認証方式に渡す認証情報です
+type Credentials struct {
+	Username string
+	Password string
+	// Token は bearer 認証のアクセストークンです
+	Token string
+}
+
+// NewAuthenticator は認証方式の名前から Authenticator を作成します。名前が空の場合は Basic 認証です。
+func NewAuthenticator(method string, cred Credentials) (Authenticator, error) {
+	switch method {
+	case "", AuthBasic:
+		return &BasicAuth{Username: cred.Username, Password: cred.Password}, nil
+	case AuthApplicationPassword:
+		return &ApplicationPasswordAuth{Username: cred.Username, Password: cred.Password}, nil
+	case AuthJWT:
+		return &JWTAuth{Username: cred.Username, Password: cred.Password}, nil
+	case AuthCookie:
+		return &CookieAuth{Username: cred.Username, Password: cred.Password}, nil
+	case AuthBearer:
+		if cred.Token == "" {
+			return nil, fmt.Errorf("bearer 認証にはアクセストークンが必要です")
+		}
+		return &BearerAuth{Token: cred.Token}, nil
+	}
+	return nil, fmt.Errorf("未対応の認証方式です: %s（%s、%s、%s、%s、%s のいずれかを指定してください）",
+		method, AuthBasic, AuthApplicationPassword, AuthJWT, AuthCookie, AuthBearer)
+}
+
+// do は認証情報を設定してリクエストを送信します。
+// 認証方式が Refresher の場合、認証エラーであれば認証情報を取り直して一度だけ再送します。
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	if c.ctx != nil {
+		req = req.WithContext(c.ctx)
+	}
+	if c.Auth != nil {
+		if err := c.Auth.Authenticate(c, req); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrAuth, err)
+		}
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	refresher, ok := c.Auth.(Refresher)
+	if !ok || (resp.StatusCode != http.StatusUnauthorized && resp.StatusCode != http.StatusForbidden) {
+		return resp, nil
+	}
+	if req.Body != nil && req.GetBody == nil {
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	var errorResp struct {
+		Code string `json:"code"`
+	}
+	json.Unmarshal(data, &errorResp)
+
+	refreshed, err := refresher.Refresh(c, resp.StatusCode, errorResp.Code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+	if !refreshed {
+		resp.Body = io.NopCloser(bytes.NewReader(data))
+		return resp, nil
+	}
+	c.log().InfoContext(req.Context(), "認証情報を取り直して再送します", "status", resp.StatusCode, "code", errorResp.Code, "url", req.URL.String())
+
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	if err := c.Auth.Authenticate(c, retry); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAuth, err)
+	}
+	return c.HTTPClient.Do(retry)
+}
+
+// BasicAuth はユーザー名とパスワードで Basic 認証します。
+// WordPress 本体はログインパスワードでの Basic 認証に対応していないため、プラグインが必要です。
+type BasicAuth struct {
+	Username string
+	Password string
+}
+
+func (a *BasicAuth) Authenticate(c *Client, req *http.Request) error {
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(a.Username+":"+a.Password)))
+	return nil
+}
+
+// ApplicationPasswordAuth は WordPress 5.6 以降のアプリケーションパスワードで認証します。
+// 最初のリクエストの前に、サイトがアプリケーションパスワードに対応しているかを確認します。
+type ApplicationPasswordAuth struct {
+	Username string
+	Password string
+
+	mu       sync.Mutex
+	verified bool
+}
+
+func (a *ApplicationPasswordAuth) Authenticate(c *Client, req *http.Request) error {
+	if err := a.verify(req.Context(), c); err != nil {
+		return err
+	}
+	// 表示用の空白を含めたまま貼り付けても使えるよう、空白を取り除く
+	password := strings.ReplaceAll(a.Password, " ", "")
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(a.Username+":"+password)))
+	return nil
+}
+
+// verify は REST API のインデックスの authentication にアプリケーションパスワードがあるかを確認します
+func (a *ApplicationPasswordAuth) verify(ctx context.Context, c *Client) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.verified {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/wp-json/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var index struct {
+		Authentication map[string]json.RawMessage `json:"authentication"`
+	}
+	if err := c.decodeResponse(resp, &index); err != nil {
+		return fmt.Errorf("REST API の取得に失敗しました: %w", err)
+	}
+	if _, ok := index.Authentication["application-passwords"]; !ok {
+		return fmt.Errorf("このサイトはアプリケーションパスワードに対応していません（WordPress 5.6 以降で HTTPS が有効であり、プラグインなどで無効にされていないことを確認してください）")
+	}
+
+	a.verified = true
+	return nil
+}
+
+// JWTAuth は JWT Authentication for WP REST API プラグインのトークンで認証します。
+// トークンは最初のリクエストの前に取得し、期限切れなどで拒否された場合は取り直します。
+type JWTAuth struct {
+	Username string
+	Password string
+
+	mu    sync.Mutex
+	token string
+}
+
+func (a *JWTAuth) Authenticate(c *Client, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" {
+		if err := a.fetchToken(req.Context(), c); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *JWTAuth) Refresh(c *Client, statusCode int, code string) (bool, error) {
+	if statusCode != http.StatusUnauthorized && !strings.HasPrefix(code, "jwt_auth_") {
+		return false, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = ""
+	if err := a.fetchToken(c.context(), c); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// fetchToken は /jwt-auth/v1/token からトークンを取得します。呼び出し側で mu をロックしてください。
+func (a *JWTAuth) fetchToken(ctx context.Context, c *Client) error {
+	jsonData, err := json.Marshal(map[string]string{
+		"username": a.Username,
+		"password": a.Password,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/wp-json/jwt-auth/v1/token", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("JWT のエンドポイント（/wp-json/jwt-auth/v1/token）が見つかりません。JWT Authentication for WP REST API プラグインが有効か確認してください")
+	}
+	var tokenResp struct {
+		Token string `json:"token"`
+	}
+	if err := c.decodeResponse(resp, &tokenResp); err != nil {
+		return fmt.Errorf("JWT トークン取得エラー: %w", err)
+	}
+	if tokenResp.Token == "" {
+		return fmt.Errorf("JWT トークン取得エラー: レスポンスにトークンが含まれていません")
+	}
+
+	a.token = tokenResp.Token
+	return nil
+}
+
+// CookieAuth は wp-login.php でログインして得た Cookie と REST API の nonce で認証します。
+// nonce の期限が切れて拒否された場合はログインし直します。
+type CookieAuth struct {
+	Username string
+	Password string
+
+	mu    sync.Mutex
+	jar   http.CookieJar
+	nonce string
+}
+
+func (a *CookieAuth) Authenticate(c *Client, req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.nonce == "" {
+		if err := a.login(req.Context(), c); err != nil {
+			return err
+		}
+	}
+	for _, cookie := range a.jar.Cookies(req.URL) {
+		req.AddCookie(cookie)
+	}
+	req.Header.Set("X-WP-Nonce", a.nonce)
+	return nil
+}
+
+func (a *CookieAuth) Refresh(c *Client, statusCode int, code string) (bool, error) {
+	if statusCode != http.StatusUnauthorized && code != "rest_cookie_invalid_nonce" {
+		return false, nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := a.login(c.context(), c); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// login は wp-login.php でログインし、admin-ajax.php から REST API の nonce を取得します。
+// 呼び出し側で mu をロックしてください。
+func (a *CookieAuth) login(ctx context.Context, c *Client) error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{Transport: c.HTTPClient.Transport, Jar: jar}
+
+	loginURL, err := url.Parse(c.BaseURL + "/wp-login.php")
+	if err != nil {
+		return err
+	}
+	// wp-login.php は Cookie が使えるかをテスト用の Cookie で確認する
+	jar.SetCookies(loginURL, []*http.Cookie{{Name: "wordpress_test_cookie", Value: "WP Cookie check"}})
+
+	form := url.Values{
+		"log":        {a.Username},
+		"pwd":        {a.Password},
+		"testcookie": {"1"},
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", loginURL.String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	loggedIn := false
+	for _, cookie := range jar.Cookies(loginURL) {
+		if strings.HasPrefix(cookie.Name, "wordpress_logged_in_") {
+			loggedIn = true
+		}
+	}
+	if !loggedIn {
+		return fmt.Errorf("wp-login.php でログインできませんでした。ユーザー名とパスワードを確認してください")
+	}
+
+	req, err = http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/wp-admin/admin-ajax.php?action=rest-nonce", nil)
+	if err != nil {
+		return err
+	}
+	resp, err = httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	nonce := strings.TrimSpace(string(data))
+	if resp.StatusCode != http.StatusOK || nonce == "" || nonce == "0" {
+		return fmt.Errorf("REST API の nonce を取得できませんでした: %d", resp.StatusCode)
+	}
+
+	a.jar = jar
+	a.nonce = nonce
+	return nil
+}
+
+// BearerAuth は発行済みのアクセストークン（OAuth 2.0 サーバープラグインのトークンなど）で認証します
+type BearerAuth struct {
+	Token string
+}
+
+func (a *BearerAuth) Authenticate(c *Client, req *http.Request) error {
+	req.Header.Set("Authorization", "Bearer "+a.Token)
+	return nil
+}
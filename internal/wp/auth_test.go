@@ -0,0 +1,40 @@
+package wp_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"wp/internal/wp"
+)
+
+// 認証のためのリクエスト（インデックスの確認、トークンの取得、ログイン）も呼び出し側のコンテキストで中断する
+func TestAuthenticationUsesContext(t *testing.T) {
+	methods := []wp.Authenticator{
+		&wp.ApplicationPasswordAuth{Username: "admin", Password: "password"},
+		&wp.JWTAuth{Username: "admin", Password: "password"},
+		&wp.CookieAuth{Username: "admin", Password: "password"},
+	}
+	for _, auth := range methods {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			http.NotFound(w, r)
+		}))
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		client := wp.NewClientWithAuth(server.URL, auth).WithContext(ctx)
+		_, err := client.GetPostOfType("posts", 1)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("%T: err = %v, want context.Canceled", auth, err)
+		}
+		if n := requests.Load(); n != 0 {
+			t.Errorf("%T: %d requests sent after cancel", auth, n)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package wp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cache は1回の実行中に取得したカテゴリー・タグ・メディアを保持します。
+// Path が設定されている場合は Save で TTL 付きのキャッシュファイルとして保存され、
+// 次回の実行時に LoadCache で再利用されます。
+type Cache struct {
+	Path string
+	TTL  time.Duration
+
+	mu         sync.Mutex
+	baseURL    string
+	fetchedAt  time.Time
+	categories []Category
+	tags       []Tag
+	// categoriesFetched・tagsFetched はターム一覧を取得済みかどうかです（タグが1つもないサイトでは tags が空のまま）
+	categoriesFetched bool
+	tagsFetched       bool
+	defaultCategory   int
+	media             map[string]MediaResponse
+	postTypes         map[string]PostType
+	schemas           map[string]*PostSchema
+	users             map[string]int
+}
+
+// cacheFile はキャッシュファイルに保存する内容です
+type cacheFile struct {
+	BaseURL   string    `json:"base_url"`
+	FetchedAt time.Time `json:"fetched_at"`
+	// Categories・Tags は未取得の場合は null、1つもない場合は [] です
+	Categories []Category               `json:"categories"`
+	Tags       []Tag                    `json:"tags"`
+	Media      map[string]MediaResponse `json:"media,omitempty"`
+}
+
+// NewCache はディスクに保存しないメモリ上のキャッシュを作成します
+func NewCache() *Cache {
+	return &Cache{media: make(map[string]MediaResponse)}
+}
+
+// LoadCache はキャッシュファイルを読み込みます。
+// ファイルが存在しない場合、TTL を過ぎている場合、別サイトのキャッシュの場合は空のキャッシュを返します。
+func LoadCache(path string, ttl time.Duration, baseURL string) (*Cache, error) {
+	cache := NewCache()
+	cache.Path = path
+	cache.TTL = ttl
+	cache.baseURL = baseURL
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("キャッシュ読み取りエラー: %w", err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		// 壊れたキャッシュは捨てて作り直す
+		return cache, nil
+	}
+	if file.BaseURL != baseURL || (ttl > 0 && time.Since(file.FetchedAt) > ttl) {
+		return cache, nil
+	}
+
+	cache.fetchedAt = file.FetchedAt
+	cache.categories, cache.categoriesFetched = file.Categories, file.Categories != nil
+	cache.tags, cache.tagsFetched = file.Tags, file.Tags != nil
+	if file.Media != nil {
+		cache.media = file.Media
+	}
+	return cache, nil
+}
+
+// Save はキャッシュをファイルに書き込みます。Path が空の場合は何もしません。
+func (c *Cache) Save() error {
+	if c == nil || c.Path == "" {
+		return nil
+	}
+
+	c.mu.Lock()
+	file := cacheFile{
+		BaseURL:   c.baseURL,
+		FetchedAt: c.fetchedAt,
+		Media:     c.media,
+	}
+	if c.categoriesFetched {
+		file.Categories = append([]Category{}, c.categories...)
+	}
+	if c.tagsFetched {
+		file.Tags = append([]Tag{}, c.tags...)
+	}
+	c.mu.Unlock()
+
+	if file.FetchedAt.IsZero() {
+		file.FetchedAt = time.Now()
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("キャッシュのJSON変換エラー: %w", err)
+	}
+	if err := os.WriteFile(c.Path, data, 0600); err != nil {
+		return fmt.Errorf("キャッシュ書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// touch は初回取得時刻を記録します。呼び出し側で mu を保持していること。
+func (c *Cache) touch() {
+	if c.fetchedAt.IsZero() {
+		c.fetchedAt = time.Now()
+	}
+}
+
+// Categories はキャッシュ済みのカテゴリー一覧を返します。未取得の場合は取得します。
+// 取得中はキャッシュのロックを保持しません。
+func (c *Client) Categories() ([]Category, error) {
+	c.Cache.mu.Lock()
+	categories, fetched := c.Cache.categories, c.Cache.categoriesFetched
+	c.Cache.mu.Unlock()
+	if fetched {
+		return categories, nil
+	}
+
+	categories, err := getAllPages[Category](c, "/wp-json/wp/v2/categories")
+	if err != nil {
+		return nil, err
+	}
+
+	c.Cache.mu.Lock()
+	defer c.Cache.mu.Unlock()
+	// 同時に取得した場合は先に記録した一覧を使う
+	if !c.Cache.categoriesFetched {
+		c.Cache.categories, c.Cache.categoriesFetched = categories, true
+		c.Cache.touch()
+	}
+	return c.Cache.categories, nil
+}
+
+// Tags はキャッシュ済みのタグ一覧を返します。未取得の場合は取得します。
+// 取得中はキャッシュのロックを保持しません。
+func (c *Client) Tags() ([]Tag, error) {
+	c.Cache.mu.Lock()
+	tags, fetched := c.Cache.tags, c.Cache.tagsFetched
+	c.Cache.mu.Unlock()
+	if fetched {
+		return tags, nil
+	}
+
+	tags, err := getAllPages[Tag](c, "/wp-json/wp/v2/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	c.Cache.mu.Lock()
+	defer c.Cache.mu.Unlock()
+	if !c.Cache.tagsFetched {
+		c.Cache.tags, c.Cache.tagsFetched = tags, true
+		c.Cache.touch()
+	}
+	return c.Cache.tags, nil
+}
+
+func (c *Cache) addCategory(category Category) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.categoriesFetched {
+		c.categories = append(c.categories, category)
+	}
+}
+
+func (c *Cache) addTag(tag Tag) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tagsFetched {
+		c.tags = append(c.tags, tag)
+	}
+}
+
+// invalidateTerms は他の実行者による変更を取り込むため、ターム一覧を破棄します。
+// lookupMedia などと同じく、キャッシュのない API（nil）でも呼び出せます。
+func (c *Cache) invalidateTerms() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.categories, c.categoriesFetched = nil, false
+	c.tags, c.tagsFetched = nil, false
+}
+
+func (c *Cache) lookupMedia(hash string) (MediaResponse, bool) {
+	if c == nil {
+		return MediaResponse{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	media, ok := c.media[hash]
+	return media, ok
+}
+
+func (c *Cache) addMedia(hash string, media MediaResponse) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.media[hash] = media
+	c.touch()
+}
+
+// findCategory はカテゴリー名を大文字小文字を区別せずに検索します
+func findCategory(categories []Category, name string) (Category, bool) {
+	for _, cat := range categories {
+		if strings.EqualFold(cat.Name, name) {
+			return cat, true
+		}
+	}
+	return Category{}, false
+}
+
+// findTag はタグ名を大文字小文字を区別せずに検索します
+func findTag(tags []Tag, name string) (Tag, bool) {
+	for _, tag := range tags {
+		if strings.EqualFold(tag.Name, name) {
+			return tag, true
+		}
+	}
+	return Tag{}, false
+}
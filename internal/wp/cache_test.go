@@ -0,0 +1,96 @@
+package wp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+// countingServer は fake のタームの一覧の取得回数を数えるサーバーを起動します
+func countingServer(t *testing.T, fake *wptest.Fake) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var lists atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && (strings.HasSuffix(r.URL.Path, "/tags") || strings.HasSuffix(r.URL.Path, "/categories")) {
+			lists.Add(1)
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	fake.BaseURL = server.URL
+	return server, &lists
+}
+
+func TestCacheEmptyTagList(t *testing.T) {
+	fake := wptest.NewFake()
+	server, lists := countingServer(t, fake)
+	client := wp.NewClient(server.URL, "admin", "password")
+
+	for i := 0; i < 3; i++ {
+		tags, err := client.Tags()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tags) != 0 {
+			t.Fatalf("tags = %v, want none", tags)
+		}
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("tag list fetched %d times, want 1", got)
+	}
+
+	// 作成したタグは取得済みの空の一覧に追加される
+	if _, _, err := wp.ResolveTags(client, []string{"Go"}); err != nil {
+		t.Fatal(err)
+	}
+	tags, err := client.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "Go" {
+		t.Errorf("tags = %v, want [Go]", tags)
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("tag list fetched %d times, want 1", got)
+	}
+}
+
+func TestCacheFileKeepsEmptyTagList(t *testing.T) {
+	fake := wptest.NewFake()
+	server, lists := countingServer(t, fake)
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	cache, err := wp.LoadCache(path, time.Hour, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := wp.NewClient(server.URL, "admin", "password")
+	client.Cache = cache
+	if _, err := client.Tags(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 次の実行ではキャッシュファイルの空の一覧を使う
+	cache, err = wp.LoadCache(path, time.Hour, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client = wp.NewClient(server.URL, "admin", "password")
+	client.Cache = cache
+	if _, err := client.Tags(); err != nil {
+		t.Fatal(err)
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("tag list fetched %d times, want 1", got)
+	}
+}
@@ -0,0 +1,316 @@
+package wp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// redactedHeaders は記録するときに値を伏せるヘッダーです
+var redactedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "X-WP-Nonce"}
+
+// reSecretJSON と reSecretForm は本文中のパスワードとトークン（JWT の取得、wp-login.php のログイン）です
+var (
+	reSecretJSON = regexp.MustCompile(`("(?:password|token)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	reSecretForm = regexp.MustCompile(`(^|&)(pwd|password)=[^&]*`)
+)
+
+const redacted = "REDACTED"
+
+// Interaction は記録したリクエストとレスポンスの組です
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest は記録したリクエストです
+type RecordedRequest struct {
+	Method string       `json:"method"`
+	URL    string       `json:"url"`
+	Header http.Header  `json:"header,omitempty"`
+	Body   RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedResponse は記録したレスポンスです
+type RecordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Header     http.Header  `json:"header,omitempty"`
+	Body       RecordedBody `json:"body,omitempty"`
+}
+
+// RecordedBody はリクエスト・レスポンスの本文です。
+// UTF-8 のテキストはそのまま、画像などのバイナリは base64 で保存します。
+type RecordedBody []byte
+
+func (b RecordedBody) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(map[string]string{"base64": base64.StdEncoding.EncodeToString(b)})
+}
+
+func (b *RecordedBody) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = RecordedBody(text)
+		return nil
+	}
+	var encoded struct {
+		Base64 string `json:"base64"`
+	}
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+	decoded, err := base64.StdEncoding.DecodeString(encoded.Base64)
+	if err != nil {
+		return err
+	}
+	*b = decoded
+	return nil
+}
+
+// Recorder はリクエストとレスポンスの組を1件ずつ Dir に JSON ファイルとして保存する http.RoundTripper です。
+// Authorization などの認証情報は伏せて保存します。
+type Recorder struct {
+	Dir string
+	// Transport は実際にリクエストを送信するトランスポートです。nil の場合は http.DefaultTransport を使います。
+	Transport http.RoundTripper
+
+	mu sync.Mutex
+	n  int
+}
+
+// NewRecorder は dir に記録する Recorder を作成します。
+// 別の記録と混ざらないよう、dir が空でない場合はエラーを返します。
+func NewRecorder(dir string, transport http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("記録先のディレクトリを作成できません: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("記録先のディレクトリを読み取れません: %w", err)
+	}
+	if len(entries) > 0 {
+		return nil, fmt.Errorf("記録先のディレクトリが空ではありません: %s", dir)
+	}
+	return &Recorder{Dir: dir, Transport: transport}, nil
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	var reqBody []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		reqBody = data
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: redactHeader(req.Header),
+			Body:   redactBody(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     redactHeader(resp.Header),
+			Body:       redactBody(respBody),
+		},
+	}
+	// admin-ajax.php が返す REST API の nonce は本文そのものが認証情報になる
+	if req.URL.Query().Get("action") == "rest-nonce" {
+		interaction.Response.Body = RecordedBody(redacted)
+	}
+	if err := r.save(req, interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save は記録を連番のファイル名（0001-GET-wp-json-wp-v2-posts-1.json など）で保存します
+func (r *Recorder) save(req *http.Request, interaction Interaction) error {
+	data, err := json.MarshalIndent(interaction, "", "  ")
+	if err != nil {
+		return fmt.Errorf("記録のJSON変換エラー: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.n++
+	name := fmt.Sprintf("%04d-%s%s.json", r.n, req.Method, cassetteName(req.URL.Path))
+	if err := os.WriteFile(filepath.Join(r.Dir, name), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("記録の書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// cassetteName は URL のパスをファイル名に使える形にします
+func cassetteName(p string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(p, "/") {
+		if part == "" {
+			continue
+		}
+		b.WriteByte('-')
+		for _, c := range part {
+			if c < 0x80 && (c == '-' || c == '_' || c == '.' || c >= '0' && c <= '9' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z') {
+				b.WriteRune(c)
+			} else {
+				b.WriteByte('_')
+			}
+		}
+		if b.Len() > 80 {
+			break
+		}
+	}
+	return b.String()
+}
+
+func redactHeader(header http.Header) http.Header {
+	redactedHeader := header.Clone()
+	for _, name := range redactedHeaders {
+		if len(redactedHeader.Values(name)) > 0 {
+			redactedHeader.Set(name, redacted)
+		}
+	}
+	return redactedHeader
+}
+
+func redactBody(body []byte) RecordedBody {
+	if !utf8.Valid(body) {
+		return body
+	}
+	body = reSecretJSON.ReplaceAll(body, []byte(`$1"`+redacted+`"`))
+	return reSecretForm.ReplaceAll(body, []byte(`$1$2=`+redacted))
+}
+
+// Replayer は Recorder で記録したレスポンスを返す http.RoundTripper です。ネットワークには接続しません。
+// リクエストはメソッドと URL が一致する記録に、記録した順に対応させます。
+// ホストが異なる場合も、パスとクエリが一致すれば対応させます。
+type Replayer struct {
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// LoadReplayer は dir に記録された JSON ファイルをファイル名の順に読み込みます
+func LoadReplayer(dir string) (*Replayer, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("記録が見つかりません: %s", dir)
+	}
+	sort.Strings(names)
+
+	r := &Replayer{}
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, fmt.Errorf("記録の読み取りエラー: %w", err)
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(data, &interaction); err != nil {
+			return nil, fmt.Errorf("記録のJSONパースエラー（%s）: %w", filepath.Base(name), err)
+		}
+		r.interactions = append(r.interactions, interaction)
+	}
+	r.used = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// Site は最初の記録のサイトの URL（スキームとホスト）を返します
+func (r *Replayer) Site() string {
+	u, err := url.Parse(r.interactions[0].Request.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := r.find(req, true)
+	if i < 0 {
+		i = r.find(req, false)
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("記録されていないリクエストです: %s %s", req.Method, req.URL)
+	}
+	r.used[i] = true
+
+	recorded := r.interactions[i].Response
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// find はまだ使っていない記録のうち、リクエストに対応する最初のものを返します
+func (r *Replayer) find(req *http.Request, sameHost bool) int {
+	for i, interaction := range r.interactions {
+		if r.used[i] || interaction.Request.Method != req.Method {
+			continue
+		}
+		u, err := url.Parse(interaction.Request.URL)
+		if err != nil {
+			continue
+		}
+		if sameHost && u.Host != req.URL.Host {
+			continue
+		}
+		if u.Path == req.URL.Path && u.Query().Encode() == req.URL.Query().Encode() {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,153 @@
+package wp_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+// 記録した通信を別のホストのクライアントで再生すると、同じレスポンスが返る
+func TestRecordAndReplay(t *testing.T) {
+	fake := wptest.NewFake()
+	fake.Username, fake.Password = "admin", "app password"
+	server := wptest.NewServer(fake)
+	dir := t.TempDir()
+	recorder, err := wp.NewRecorder(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	image := []byte("\x89PNG\r\n\x1a\n\x00\xff\xfe")
+
+	client := wp.NewClient(server.URL, "admin", "app password")
+	client.HTTPClient = &http.Client{Transport: recorder}
+	created, err := client.CreatePostOfType("posts", wp.PostRequest{Title: "記録", Content: "<p>本文</p>", Status: "draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	media, err := client.UploadMedia("y.png", image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	// 画像はテキストとして保存できないため base64 で保存する
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("recorded %d files, want 2: %v", len(files), files)
+	}
+	if data, _ := os.ReadFile(files[1]); !bytes.Contains(data, []byte(`"base64"`)) {
+		t.Errorf("upload request was not saved as base64:\n%s", data)
+	}
+
+	replayer, err := wp.LoadReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if site := replayer.Site(); site != server.URL {
+		t.Errorf("Site = %q, want %q", site, server.URL)
+	}
+	replay := wp.NewClient("http://replay.test", "admin", "app password")
+	replay.HTTPClient = &http.Client{Transport: replayer}
+	post, err := replay.CreatePostOfType("posts", wp.PostRequest{Title: "記録", Content: "<p>本文</p>", Status: "draft"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.ID != created.ID || post.Title.Raw != "記録" {
+		t.Errorf("replayed post = %d %q, want %d %q", post.ID, post.Title.Raw, created.ID, "記録")
+	}
+	replayedMedia, err := replay.UploadMedia("y.png", image)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayedMedia.ID != media.ID || replayedMedia.URL != media.URL {
+		t.Errorf("replayed media = %+v, want %+v", replayedMedia, media)
+	}
+
+	// 記録は1回だけ使い、記録されていないリクエストはネットワークに接続せずにエラーにする
+	if _, err := replay.UploadMedia("y.png", image); err == nil {
+		t.Error("a request replayed twice succeeded")
+	}
+	if _, err := replay.GetPostOfType("posts", created.ID); err == nil {
+		t.Error("an unrecorded request succeeded")
+	}
+}
+
+// どの認証方式でも、パスワード・トークン・Cookie・nonce を記録に保存しない
+func TestRecorderRedactsCredentials(t *testing.T) {
+	const password = "secret-password"
+	secrets := []string{
+		password,
+		base64.StdEncoding.EncodeToString([]byte("admin:" + password)),
+		"secret-jwt-token",
+		"secret-session",
+		"secret-nonce",
+	}
+
+	fake := wptest.NewFake()
+	post, err := fake.CreatePostOfType("posts", wp.PostRequest{Title: "記事", Status: "publish"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/wp-json/jwt-auth/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token": "secret-jwt-token", "user_email": "admin@example.com"}`))
+	})
+	mux.HandleFunc("/wp-login.php", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "wordpress_logged_in_abc", Value: "secret-session", Path: "/"})
+	})
+	mux.HandleFunc("/wp-admin/admin-ajax.php", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret-nonce"))
+	})
+	mux.Handle("/", fake)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	methods := []wp.Authenticator{
+		&wp.BasicAuth{Username: "admin", Password: password},
+		&wp.JWTAuth{Username: "admin", Password: password},
+		&wp.CookieAuth{Username: "admin", Password: password},
+	}
+	for _, auth := range methods {
+		dir := t.TempDir()
+		recorder, err := wp.NewRecorder(dir, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := wp.NewClientWithAuth(server.URL, auth)
+		client.HTTPClient = &http.Client{Transport: recorder}
+		if _, err := client.GetPostOfType("posts", post.ID); err != nil {
+			t.Fatalf("%T: %v", auth, err)
+		}
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, file := range files {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range secrets {
+				if strings.Contains(string(data), secret) {
+					t.Errorf("%T: %s contains %q:\n%s", auth, filepath.Base(file), secret, data)
+				}
+			}
+		}
+		if len(files) == 0 {
+			t.Errorf("%T: nothing recorded", auth)
+		}
+	}
+}
@@ -3,99 +3,135 @@ package wp
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"strings"
 )
 
-func GetCategoryIDs(client *Client, categoryNames []string) ([]int, error) {
-	url := client.BaseURL + "/wp-json/wp/v2/categories?per_page=100"
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Basic "+client.BasicAuth)
+func GetCategoryIDs(client API, categoryNames []string) ([]int, error) {
+	ids, _, err := ResolveCategories(client, categoryNames)
+	return ids, err
+}
 
-	resp, err := client.HTTPClient.Do(req)
+// ResolveCategories はカテゴリー名をIDに変換し、存在しないカテゴリーは作成します。
+// 作成したカテゴリーも返します。
+func ResolveCategories(client API, categoryNames []string) ([]int, []Category, error) {
+	categories, err := client.Categories()
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var categories []Category
-	if err := client.decodeResponse(resp, &categories); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var categoryIDs []int
+	var created []Category
 	for _, name := range categoryNames {
-		found := false
-		for _, cat := range categories {
-			if strings.EqualFold(cat.Name, name) {
-				categoryIDs = append(categoryIDs, cat.ID)
-				found = true
-				break
-			}
+		if cat, ok := findCategory(categories, name); ok {
+			categoryIDs = append(categoryIDs, cat.ID)
+			continue
 		}
 
-		if !found {
-			// カテゴリーが存在しない場合は新規作成
-			newCat, err := CreateCategory(client, name)
-			if err != nil {
-				// 作成に失敗した場合は、もう一度検索を試みる
-				for _, cat := range categories {
-					if strings.EqualFold(cat.Name, name) {
-						categoryIDs = append(categoryIDs, cat.ID)
-						found = true
-						break
-					}
-				}
-				if !found {
-					return nil, fmt.Errorf("カテゴリー作成エラー: %v", err)
-				}
-			} else {
-				categoryIDs = append(categoryIDs, newCat.ID)
+		// カテゴリーが存在しない場合は新規作成
+		newCat, err := client.CreateCategory(name)
+		if err != nil {
+			if id, ok := existingTermID(err); ok {
+				categoryIDs = append(categoryIDs, id)
+				continue
 			}
+			// 作成に失敗した場合は、他で作成された可能性があるので取得し直して検索する
+			client.SharedCache().invalidateTerms()
+			categories, listErr := client.Categories()
+			if listErr != nil {
+				return nil, nil, fmt.Errorf("カテゴリー作成エラー: %w", err)
+			}
+			cat, ok := findCategory(categories, name)
+			if !ok {
+				return nil, nil, fmt.Errorf("カテゴリー作成エラー: %w", err)
+			}
+			categoryIDs = append(categoryIDs, cat.ID)
+			continue
 		}
+		categoryIDs = append(categoryIDs, newCat.ID)
+		created = append(created, *newCat)
 	}
 
-	return categoryIDs, nil
+	return categoryIDs, created, nil
+}
+
+// existingTermID はタームの作成が term_exists で失敗した場合に、既に存在するタームのIDを返します
+func existingTermID(err error) (int, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == "term_exists" && apiErr.TermID != 0 {
+		return apiErr.TermID, true
+	}
+	return 0, false
 }
 
 type CreateCategoryRequest struct {
 	Name string `json:"name"`
 }
 
-func CreateCategory(client *Client, name string) (*Category, error) {
+func CreateCategory(client API, name string) (*Category, error) {
+	return client.CreateCategory(name)
+}
+
+// CreateCategory はカテゴリーを作成し、取得済みのカテゴリー一覧に追加します
+func (c *Client) CreateCategory(name string) (*Category, error) {
 	categoryReq := CreateCategoryRequest{
 		Name: name,
 	}
 
 	jsonData, err := json.Marshal(categoryReq)
 	if err != nil {
-		return nil, fmt.Errorf("JSON変換エラー: %v", err)
+		return nil, fmt.Errorf("JSON変換エラー: %w", err)
 	}
 
-	url := client.BaseURL + "/wp-json/wp/v2/categories"
+	url := c.BaseURL + "/wp-json/wp/v2/categories"
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+client.BasicAuth)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	var category Category
-	if err := client.decodeResponse(resp, &category); err != nil {
+	if err := c.decodeResponse(resp, &category); err != nil {
 		return nil, err
 	}
+	c.Cache.addCategory(category)
 
 	return &category, nil
-}
\ No newline at end of file
+}
+
+// DefaultCategory はカテゴリーを指定しない投稿に WordPress が設定するカテゴリー（設定の default_category）のIDを返します。
+// 設定を読む権限がない場合は、インストール時の既定のカテゴリー（未分類、ID 1）とします。
+func (c *Client) DefaultCategory() (int, error) {
+	c.Cache.mu.Lock()
+	id := c.Cache.defaultCategory
+	c.Cache.mu.Unlock()
+	if id != 0 {
+		return id, nil
+	}
+
+	var settings struct {
+		DefaultCategory int `json:"default_category"`
+	}
+	if err := getJSON(c, "/wp-json/wp/v2/settings", &settings); err != nil {
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return 0, fmt.Errorf("設定取得エラー: %w", err)
+		}
+	}
+	if settings.DefaultCategory == 0 {
+		settings.DefaultCategory = 1
+	}
+
+	c.Cache.mu.Lock()
+	defer c.Cache.mu.Unlock()
+	c.Cache.defaultCategory = settings.DefaultCategory
+	return settings.DefaultCategory, nil
+}
@@ -2,45 +2,80 @@ package wp
 
 import (
 	"bytes"
-	"encoding/base64"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 )
 
 type Client struct {
-	BaseURL    string
-	BasicAuth  string
+	BaseURL string
+	// Auth はリクエストに認証情報を設定します
+	Auth       Authenticator
 	HTTPClient *http.Client
+	// Cache は実行中に取得したターム・メディアを共有するためのキャッシュです
+	Cache *Cache
+	// Logger は認証のやり直しや公開処理の進み具合を出力します。nil の場合は出力しません。
+	Logger *slog.Logger
+
+	ctx context.Context
 }
 
+// NewClient はユーザー名とパスワードの Basic 認証でクライアントを作成します
 func NewClient(baseURL, username, password string) *Client {
-	auth := username + ":" + password
-	basicAuth := base64.StdEncoding.EncodeToString([]byte(auth))
+	return NewClientWithAuth(baseURL, &BasicAuth{Username: username, Password: password})
+}
 
+// NewClientWithAuth は認証方式を指定してクライアントを作成します
+func NewClientWithAuth(baseURL string, auth Authenticator) *Client {
 	return &Client{
-		BaseURL:    baseURL,
-		BasicAuth:  basicAuth,
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Auth:       auth,
 		HTTPClient: &http.Client{},
+		Cache:      NewCache(),
+	}
+}
+
+// WithContext はリクエストに ctx を使うクライアントを返します。認証とキャッシュは元のクライアントと共有します。
+func (c *Client) WithContext(ctx context.Context) *Client {
+	copied := *c
+	copied.ctx = ctx
+	return &copied
+}
+
+// context は WithContext で設定したコンテキストを返します。設定されていない場合は context.Background です。
+func (c *Client) context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
 	}
+	return context.Background()
 }
 
 func (c *Client) CreatePost(post PostRequest) (*PostResponse, error) {
+	return c.CreatePostOfType("posts", post)
+}
+
+// CreatePostOfType は REST base（posts、pages、カスタム投稿タイプ）を指定して投稿を作成します
+func (c *Client) CreatePostOfType(restBase string, post PostRequest) (*PostResponse, error) {
 	jsonData, err := json.Marshal(post)
 	if err != nil {
-		return nil, fmt.Errorf("JSON変換エラー: %v", err)
+		return nil, fmt.Errorf("JSON変換エラー: %w", err)
 	}
 
-	url := c.BaseURL + "/wp-json/wp/v2/posts"
+	url := c.BaseURL + "/wp-json/wp/v2/" + restBase
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+c.BasicAuth)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -55,22 +90,35 @@ func (c *Client) CreatePost(post PostRequest) (*PostResponse, error) {
 }
 
 func (c *Client) UpdatePost(postID int, post PostRequest) (*PostResponse, error) {
-	jsonData, err := json.Marshal(post)
+	return c.UpdatePostOfType("posts", postID, post)
+}
+
+// UpdatePostOfType は REST base を指定して投稿を更新します
+func (c *Client) UpdatePostOfType(restBase string, postID int, post PostRequest) (*PostResponse, error) {
+	return c.updatePost(restBase, postID, post)
+}
+
+// UpdatePostFields は指定したフィールドだけを送信して投稿を更新します
+func (c *Client) UpdatePostFields(restBase string, postID int, fields map[string]interface{}) (*PostResponse, error) {
+	return c.updatePost(restBase, postID, fields)
+}
+
+func (c *Client) updatePost(restBase string, postID int, body interface{}) (*PostResponse, error) {
+	jsonData, err := json.Marshal(body)
 	if err != nil {
-		return nil, fmt.Errorf("JSON変換エラー: %v", err)
+		return nil, fmt.Errorf("JSON変換エラー: %w", err)
 	}
 
-	url := fmt.Sprintf("%s/wp-json/wp/v2/posts/%d", c.BaseURL, postID)
+	url := fmt.Sprintf("%s/wp-json/wp/v2/%s/%d", c.BaseURL, restBase, postID)
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+c.BasicAuth)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("X-HTTP-Method-Override", "PUT")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -84,16 +132,138 @@ func (c *Client) UpdatePost(postID int, post PostRequest) (*PostResponse, error)
 	return &postResp, nil
 }
 
+// DeletePost は投稿をゴミ箱に移します。force が true の場合はゴミ箱を経由せずに完全に削除します。
+func (c *Client) DeletePost(restBase string, postID int, force bool) error {
+	url := fmt.Sprintf("%s/wp-json/wp/v2/%s/%d", c.BaseURL, restBase, postID)
+	if force {
+		url += "?force=true"
+	}
+	req, err := http.NewRequest("DELETE", url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// ゴミ箱に移した場合は投稿、完全に削除した場合は {deleted, previous} が返る
+	var result map[string]interface{}
+	return c.decodeResponse(resp, &result)
+}
+
+// GetPostOfType は編集用コンテキスト（context=edit）で投稿を取得します
+func (c *Client) GetPostOfType(restBase string, postID int) (*PostResponse, error) {
+	url := fmt.Sprintf("%s/wp-json/wp/v2/%s/%d?context=edit", c.BaseURL, restBase, postID)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var postResp PostResponse
+	if err := c.decodeResponse(resp, &postResp); err != nil {
+		return nil, err
+	}
+
+	return &postResp, nil
+}
+
+// ListPosts は条件に合う投稿をすべてのページにわたって取得します（context=edit）
+func (c *Client) ListPosts(restBase string, query PostQuery) ([]PostResponse, error) {
+	params := url.Values{"context": {"edit"}}
+	if len(query.Include) > 0 {
+		ids := make([]string, len(query.Include))
+		for i, id := range query.Include {
+			ids[i] = strconv.Itoa(id)
+		}
+		params.Set("include", strings.Join(ids, ","))
+	}
+	if query.Slug != "" {
+		params.Set("slug", query.Slug)
+	}
+	if len(query.Status) > 0 {
+		params.Set("status", strings.Join(query.Status, ","))
+	}
+	if len(query.Fields) > 0 {
+		params.Set("_fields", strings.Join(query.Fields, ","))
+	}
+	return getAllPages[PostResponse](c, "/wp-json/wp/v2/"+restBase+"?"+params.Encode())
+}
+
+// SharedCache は Cache を返します
+func (c *Client) SharedCache() *Cache {
+	return c.Cache
+}
+
+// decodeResponse は成功したレスポンスを v にデコードします。エラーの場合は *APIError を返します。
 func (c *Client) decodeResponse(resp *http.Response, v interface{}) error {
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		var errorResp struct {
-			Code    string `json:"code"`
-			Message string `json:"message"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
 			return fmt.Errorf("APIエラー: %d", resp.StatusCode)
 		}
-		return fmt.Errorf("APIエラー: %s - %s", errorResp.Code, errorResp.Message)
+		return parseAPIError(resp.StatusCode, data)
 	}
 	return json.NewDecoder(resp.Body).Decode(v)
 }
+
+// getJSON は GET リクエストの結果を v にデコードします
+func getJSON(c *Client, path string, v interface{}) error {
+	req, err := http.NewRequest("GET", c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return c.decodeResponse(resp, v)
+}
+
+// getAllPages は一覧APIを X-WP-TotalPages に従って最後のページまで取得します
+func getAllPages[T any](c *Client, path string) ([]T, error) {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+
+	var all []T
+	for page := 1; ; page++ {
+		url := fmt.Sprintf("%s%s%sper_page=100&page=%d", c.BaseURL, path, sep, page)
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var items []T
+		err = c.decodeResponse(resp, &items)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, items...)
+
+		totalPages, _ := strconv.Atoi(resp.Header.Get("X-WP-TotalPages"))
+		if page >= totalPages {
+			break
+		}
+	}
+
+	return all, nil
+}
@@ -0,0 +1,175 @@
+package wp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Config は設定ファイル（wp.json）の内容です
+type Config struct {
+	// Default は既定のプロファイル名です。既定のプロファイルの投稿IDは記事の post_id に記録されます。
+	Default  string             `json:"default"`
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// Profile は投稿先のサイト1つの設定です
+type Profile struct {
+	URL string `json:"url"`
+	// Auth は認証方式です（basic、application-password、jwt、cookie、bearer）
+	Auth     string `json:"auth,omitempty"`
+	Username string `json:"username,omitempty"`
+	// Credential は認証情報の保存先です（env、helper、file）
+	Credential string `json:"credential,omitempty"`
+	// PasswordEnv・TokenEnv は credential が env の場合に認証情報を読み取る環境変数名です（既定は USER_PASSWORD・WP_TOKEN）
+	PasswordEnv string `json:"password_env,omitempty"`
+	TokenEnv    string `json:"token_env,omitempty"`
+	// CredentialHelper は credential が helper の場合に実行する git 形式の credential helper です
+	CredentialHelper string `json:"credential_helper,omitempty"`
+	// CredentialFile は credential が file の場合の暗号化した認証情報ファイルです
+	CredentialFile string `json:"credential_file,omitempty"`
+	DefaultStatus  string `json:"default_status,omitempty"`
+	ArticlesDir    string `json:"articles_dir,omitempty"`
+	ImagesDir      string `json:"images_dir,omitempty"`
+	// CodeLanguages は lint でコードブロックに指定できる言語です（サイトの Highlighting Code Block に設定した言語）
+	CodeLanguages []string `json:"code_languages,omitempty"`
+	// PreferredTerms は lint -prose で表記を統一する用語（誤った表記 → 正しい表記）で、既定の用語に追加します
+	PreferredTerms map[string]string `json:"preferred_terms,omitempty"`
+	// MaxSentenceLength は lint -prose で1文の最大の文字数です（省略時は 100）
+	MaxSentenceLength int `json:"max_sentence_length,omitempty"`
+}
+
+// LoadConfig は設定ファイルを読み込みます。ファイルがない場合は nil を返します。
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイル読み取りエラー: %w", err)
+	}
+
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("設定ファイルのJSONパースエラー: %w", err)
+	}
+	if len(config.Profiles) == 0 {
+		return nil, fmt.Errorf("設定ファイルにプロファイルがありません: %s", path)
+	}
+	if config.Default == "" && len(config.Profiles) == 1 {
+		for name := range config.Profiles {
+			config.Default = name
+		}
+	}
+	if _, ok := config.Profiles[config.Default]; !ok && config.Default != "" {
+		return nil, fmt.Errorf("既定のプロファイルが見つかりません: %s", config.Default)
+	}
+	for name, profile := range config.Profiles {
+		if profile.URL == "" {
+			return nil, fmt.Errorf("プロファイル %s の url がありません", name)
+		}
+		if profile.DefaultStatus != "" && !contains(postStatuses, profile.DefaultStatus) {
+			return nil, fmt.Errorf("プロファイル %s の default_status が不正です: %s（%s のいずれかを指定してください）", name, profile.DefaultStatus, strings.Join(postStatuses, ", "))
+		}
+	}
+	return &config, nil
+}
+
+// Profile はプロファイルを名前で取得します。名前が空の場合は既定のプロファイルを返します。
+func (c *Config) Profile(name string) (Profile, error) {
+	if name == "" {
+		name = c.Default
+	}
+	if name == "" {
+		return Profile{}, fmt.Errorf("-profile でプロファイルを指定してください（%s）", strings.Join(c.ProfileNames(), ", "))
+	}
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return Profile{}, fmt.Errorf("プロファイルが見つかりません: %s（%s）", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames はプロファイル名を昇順で返します
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StateKey は記事にプロファイルの投稿IDを記録するときの名前を返します。既定のプロファイルは空です。
+func (c *Config) StateKey(name string) string {
+	if name == "" || name == c.Default {
+		return ""
+	}
+	return name
+}
+
+// CredentialStore はプロファイルの認証情報の保存先を返します。
+// passphrase は暗号化した認証情報ファイルのパスフレーズを返す関数で、ファイルを使う場合だけ呼ばれます。
+func (p Profile) CredentialStore(passphrase func() (string, error)) (CredentialStore, error) {
+	switch p.Credential {
+	case "", CredentialEnv:
+		store := &EnvCredentialStore{UsernameEnv: "USER_NAME", PasswordEnv: p.PasswordEnv, TokenEnv: p.TokenEnv}
+		if store.PasswordEnv == "" {
+			store.PasswordEnv = "USER_PASSWORD"
+		}
+		if store.TokenEnv == "" {
+			store.TokenEnv = "WP_TOKEN"
+		}
+		return store, nil
+	case CredentialHelper:
+		if p.CredentialHelper == "" {
+			return nil, fmt.Errorf("credential_helper を指定してください（例: osxkeychain、libsecret、\"!pass show wp\"）")
+		}
+		return &HelperCredentialStore{Helper: p.CredentialHelper}, nil
+	case CredentialFile:
+		path := p.CredentialFile
+		if path == "" {
+			path = DefaultCredentialFile()
+		}
+		return &FileCredentialStore{Path: path, Passphrase: passphrase}, nil
+	}
+	return nil, fmt.Errorf("未対応の認証情報の保存先です: %s（%s、%s、%s のいずれかを指定してください）",
+		p.Credential, CredentialEnv, CredentialHelper, CredentialFile)
+}
+
+// Credentials は保存先からプロファイルの認証情報を読み取ります。
+// ユーザー名が保存されていない場合はプロファイルの username を使います。
+func (p Profile) Credentials(store CredentialStore) (Credentials, error) {
+	cred, err := store.Get(p.URL)
+	if err != nil {
+		return Credentials{}, err
+	}
+	if cred == nil {
+		if _, ok := store.(*EnvCredentialStore); ok {
+			return Credentials{Username: p.Username}, nil
+		}
+		return Credentials{}, fmt.Errorf("%s の認証情報が保存されていません。login を実行してください", p.URL)
+	}
+	// 環境変数の場合は設定ファイルのユーザー名を USER_NAME より優先する
+	_, fromEnv := store.(*EnvCredentialStore)
+	if p.Username != "" && (cred.Username == "" || fromEnv) {
+		cred.Username = p.Username
+	}
+	return *cred, nil
+}
+
+// NewClient はプロファイルの URL と認証方式でクライアントを作成します
+func (p Profile) NewClient(cred Credentials) (*Client, error) {
+	// credential helper はトークンもパスワードとして保存する
+	if p.Auth == AuthBearer && cred.Token == "" {
+		cred.Token = cred.Password
+	}
+	auth, err := NewAuthenticator(p.Auth, cred)
+	if err != nil {
+		return nil, err
+	}
+	return NewClientWithAuth(p.URL, auth), nil
+}
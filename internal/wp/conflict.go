@@ -0,0 +1,112 @@
+package wp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 画像の src をファイル名だけにするための正規表現。
+// WordPress がアップロード時に付ける連番（-1）やサイズ（-300x200）も取り除く。
+var reImageSource = regexp.MustCompile(`(<img[^>]*?src=")(?:[^"]*/)?([^"/]+?)(?:-\d+x\d+|-\d+)?(\.[A-Za-z0-9]+)"`)
+
+// 差分を読みやすくするため、これらの閉じタグの後で改行する
+var reBlockEnd = regexp.MustCompile(`(</(?:p|li|ul|ol|tr|thead|tbody|table|h[1-6])>)\n?`)
+
+// normalizeForDiff は表示用の差分を取るために本文を正規化します。
+// アップロード前のローカルの画像パスとアップロード後のURLを同じものとして扱います。
+func normalizeForDiff(content string) string {
+	content = reImageSource.ReplaceAllString(normalizeContent(content), `$1$2$3"`)
+	return strings.TrimSpace(reBlockEnd.ReplaceAllString(content, "$1\n"))
+}
+
+// NewSyncState は投稿・更新後のレスポンスから同期状態を作成します
+func NewSyncState(post *PostResponse) *SyncState {
+	return &SyncState{
+		ModifiedGMT: post.ModifiedGMT,
+		ContentHash: HashContent(post.Content.Raw),
+	}
+}
+
+// HashContent は本文の比較用ハッシュを返します。行末の空白の違いは無視します。
+func HashContent(content string) string {
+	sum := sha256.Sum256([]byte(normalizeContent(content)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Conflict は最後の投稿以降に WordPress 上で投稿が変更されたことを表します
+type Conflict struct {
+	State  *SyncState
+	Remote *PostResponse
+	// ContentChanged は本文が変更されている場合に true です
+	ContentChanged bool
+	// Base は最後に投稿した内容のリビジョンです。見つからない場合は nil です。
+	Base *Revision
+}
+
+// CheckConflict は最後の投稿時の状態と現在の投稿を比較します。
+// 同期状態が記録されていない記事、または変更がない場合は nil を返します。
+func CheckConflict(client API, restBase string, state *SyncState, remote *PostResponse) (*Conflict, error) {
+	if state == nil || state.ModifiedGMT == "" || state.ModifiedGMT == remote.ModifiedGMT {
+		return nil, nil
+	}
+
+	conflict := &Conflict{
+		State:          state,
+		Remote:         remote,
+		ContentChanged: HashContent(remote.Content.Raw) != state.ContentHash,
+	}
+	if !conflict.ContentChanged {
+		return conflict, nil
+	}
+
+	// 最後に投稿した本文はリビジョンの中からハッシュが一致するものを探す
+	revisions, err := GetRevisions(client, restBase, remote.ID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if HashContent(revisions[i].Content.Raw) == state.ContentHash {
+			conflict.Base = &revisions[i]
+			break
+		}
+	}
+	return conflict, nil
+}
+
+// Error は競合の概要を返します
+func (c *Conflict) Error() string {
+	what := "本文以外の項目"
+	if c.ContentChanged {
+		what = "本文"
+	}
+	return fmt.Sprintf("最後の投稿（%s）以降に WordPress 上で%sが変更されています（%s）", c.State.ModifiedGMT, what, c.Remote.ModifiedGMT)
+}
+
+// ThreeWayDiff は最後に投稿した内容を基準に、WordPress 上の変更とローカルの変更を並べて表示します。
+// 基準となるリビジョンが見つからない場合は WordPress 上の内容とローカルの内容を直接比較します。
+func (c *Conflict) ThreeWayDiff(local string) string {
+	if !c.ContentChanged {
+		return ""
+	}
+
+	var b strings.Builder
+	if c.Base == nil {
+		b.WriteString("最後に投稿した内容がリビジョンに見つからないため、WordPress 上の内容とローカルの内容を比較します\n")
+		b.WriteString(UnifiedDiff("WordPress", "ローカル", normalizeForDiff(c.Remote.Content.Raw), normalizeForDiff(local), 3))
+		return b.String()
+	}
+
+	base := normalizeForDiff(c.Base.Content.Raw)
+	b.WriteString("=== WordPress 上での変更（最後に投稿した内容 → WordPress）\n")
+	b.WriteString(UnifiedDiff("最後に投稿した内容", "WordPress", base, normalizeForDiff(c.Remote.Content.Raw), 3))
+	b.WriteString("=== ローカルの変更（最後に投稿した内容 → ローカル）\n")
+	if d := UnifiedDiff("最後に投稿した内容", "ローカル", base, normalizeForDiff(local), 3); d != "" {
+		b.WriteString(d)
+	} else {
+		b.WriteString("（変更なし）\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,332 @@
+package wp
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// 認証情報の保存先の種類（プロファイルの credential、または WP_CREDENTIAL で指定する値）
+const (
+	CredentialEnv    = "env"
+	CredentialHelper = "helper"
+	CredentialFile   = "file"
+)
+
+// CredentialStore はサイトごとの認証情報の保存先です。site はサイトの URL です。
+type CredentialStore interface {
+	// Get は保存されている認証情報を返します。保存されていない場合は nil を返します。
+	Get(site string) (*Credentials, error)
+	Store(site string, cred Credentials) error
+	Erase(site string) error
+}
+
+// EnvCredentialStore は環境変数から認証情報を読み取ります。保存と削除はできません。
+type EnvCredentialStore struct {
+	UsernameEnv string
+	PasswordEnv string
+	TokenEnv    string
+}
+
+func (s *EnvCredentialStore) Get(site string) (*Credentials, error) {
+	cred := Credentials{
+		Username: os.Getenv(s.UsernameEnv),
+		Password: os.Getenv(s.PasswordEnv),
+		Token:    os.Getenv(s.TokenEnv),
+	}
+	if cred == (Credentials{}) {
+		return nil, nil
+	}
+	return &cred, nil
+}
+
+func (s *EnvCredentialStore) Store(site string, cred Credentials) error {
+	return fmt.Errorf("環境変数の認証情報は保存できません。credential に %s または %s を指定してください", CredentialHelper, CredentialFile)
+}
+
+func (s *EnvCredentialStore) Erase(site string) error {
+	return fmt.Errorf("環境変数の認証情報は削除できません。%s を .env から取り除いてください", s.PasswordEnv)
+}
+
+// HelperCredentialStore は git の credential helper と同じ形式のコマンドで認証情報を読み書きします。
+// Helper が "!" で始まる場合はシェルのコマンド、絶対パスの場合はそのコマンド、
+// それ以外は git-credential-<Helper>（osxkeychain、libsecret、manager など）を実行します。
+type HelperCredentialStore struct {
+	Helper string
+}
+
+func (s *HelperCredentialStore) Get(site string) (*Credentials, error) {
+	attrs, err := credentialAttrs(site, nil)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s.run("get", attrs)
+	if err != nil {
+		return nil, err
+	}
+
+	var cred Credentials
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "username":
+			cred.Username = value
+		case "password":
+			cred.Password = value
+		}
+	}
+	if cred.Password == "" {
+		return nil, nil
+	}
+	return &cred, nil
+}
+
+func (s *HelperCredentialStore) Store(site string, cred Credentials) error {
+	attrs, err := credentialAttrs(site, &cred)
+	if err != nil {
+		return err
+	}
+	_, err = s.run("store", attrs)
+	return err
+}
+
+func (s *HelperCredentialStore) Erase(site string) error {
+	attrs, err := credentialAttrs(site, nil)
+	if err != nil {
+		return err
+	}
+	_, err = s.run("erase", attrs)
+	return err
+}
+
+// run は helper に action（get、store、erase）と属性を渡して実行します
+func (s *HelperCredentialStore) run(action, attrs string) ([]byte, error) {
+	var cmd *exec.Cmd
+	switch {
+	case strings.HasPrefix(s.Helper, "!"):
+		cmd = exec.Command("sh", "-c", s.Helper[1:]+" "+action)
+	case filepath.IsAbs(s.Helper):
+		cmd = exec.Command(s.Helper, action)
+	default:
+		cmd = exec.Command("git-credential-"+s.Helper, action)
+	}
+	cmd.Stdin = strings.NewReader(attrs)
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper（%s %s）の実行エラー: %w", s.Helper, action, err)
+	}
+	return out, nil
+}
+
+// credentialAttrs は git の credential helper に渡す属性を組み立てます。
+// bearer 認証のトークンはパスワードとして保存します。
+func credentialAttrs(site string, cred *Credentials) (string, error) {
+	u, err := url.Parse(site)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("サイトの URL が不正です: %s", site)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "protocol=%s\nhost=%s\n", u.Scheme, u.Host)
+	if path := strings.Trim(u.Path, "/"); path != "" {
+		fmt.Fprintf(&b, "path=%s\n", path)
+	}
+	if cred != nil {
+		password := cred.Password
+		if password == "" {
+			password = cred.Token
+		}
+		fmt.Fprintf(&b, "username=%s\npassword=%s\n", cred.Username, password)
+	}
+	b.WriteString("\n")
+	return b.String(), nil
+}
+
+// pbkdf2Iterations は暗号化ファイルの鍵を導出するときの反復回数です
+const pbkdf2Iterations = 600000
+
+// FileCredentialStore はパスフレーズで暗号化したファイルに認証情報を保存します。
+// 鍵は PBKDF2-HMAC-SHA256 で導出し、AES-256-GCM で暗号化します。
+type FileCredentialStore struct {
+	Path string
+	// Passphrase はパスフレーズを返します。ファイルを読み書きするときに一度だけ呼ばれます。
+	Passphrase func() (string, error)
+
+	passphrase string
+}
+
+// encryptedFile は暗号化ファイルの形式です
+type encryptedFile struct {
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Data       []byte `json:"data"`
+}
+
+func (s *FileCredentialStore) Get(site string) (*Credentials, error) {
+	creds, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	cred, ok := creds[site]
+	if !ok {
+		return nil, nil
+	}
+	return &cred, nil
+}
+
+func (s *FileCredentialStore) Store(site string, cred Credentials) error {
+	creds, err := s.load()
+	if err != nil {
+		return err
+	}
+	creds[site] = cred
+	return s.save(creds)
+}
+
+func (s *FileCredentialStore) Erase(site string) error {
+	creds, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := creds[site]; !ok {
+		return nil
+	}
+	delete(creds, site)
+	return s.save(creds)
+}
+
+// load はファイルを復号して、サイトごとの認証情報を返します。ファイルがない場合は空です。
+func (s *FileCredentialStore) load() (map[string]Credentials, error) {
+	creds := make(map[string]Credentials)
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return creds, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("認証情報ファイル読み取りエラー: %w", err)
+	}
+
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("認証情報ファイルのJSONパースエラー: %w", err)
+	}
+	if file.KDF != "pbkdf2-sha256" || file.Iterations <= 0 {
+		return nil, fmt.Errorf("未対応の認証情報ファイルです: %s", s.Path)
+	}
+
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := gcm.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("認証情報ファイルを復号できません。パスフレーズが違うか、ファイルが壊れています")
+	}
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("認証情報ファイルのJSONパースエラー: %w", err)
+	}
+	return creds, nil
+}
+
+// save は認証情報を新しいソルトとノンスで暗号化して書き込みます
+func (s *FileCredentialStore) save(creds map[string]Credentials) error {
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return err
+	}
+
+	file := encryptedFile{
+		KDF:        "pbkdf2-sha256",
+		Iterations: pbkdf2Iterations,
+		Salt:       make([]byte, 16),
+	}
+	if _, err := rand.Read(file.Salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(passphrase, file.Salt, file.Iterations)
+	if err != nil {
+		return err
+	}
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(file.Nonce); err != nil {
+		return err
+	}
+	file.Data = gcm.Seal(nil, file.Nonce, plain, nil)
+
+	data, err := json.MarshalIndent(file, "", "    ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return fmt.Errorf("認証情報ファイル書き込みエラー: %w", err)
+	}
+	if err := os.WriteFile(s.Path, append(data, '\n'), 0600); err != nil {
+		return fmt.Errorf("認証情報ファイル書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+func (s *FileCredentialStore) getPassphrase() (string, error) {
+	if s.passphrase != "" {
+		return s.passphrase, nil
+	}
+	if s.Passphrase == nil {
+		return "", fmt.Errorf("認証情報ファイルのパスフレーズが指定されていません")
+	}
+	passphrase, err := s.Passphrase()
+	if err != nil {
+		return "", err
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("パスフレーズが空です")
+	}
+	s.passphrase = passphrase
+	return passphrase, nil
+}
+
+// DefaultCredentialFile は暗号化した認証情報ファイルの既定の場所を返します
+func DefaultCredentialFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "wp", "credentials.json")
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2.Key([]byte(passphrase), salt, iterations, 32, sha256.New)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
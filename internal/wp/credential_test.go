@@ -0,0 +1,67 @@
+package wp_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"wp/internal/wp"
+)
+
+func passphrase(s string) func() (string, error) {
+	return func() (string, error) { return s, nil }
+}
+
+func TestFileCredentialStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wp", "credentials.json")
+	site := "https://example.com"
+	cred := wp.Credentials{Username: "admin", Password: "abcd efgh ijkl"}
+
+	store := &wp.FileCredentialStore{Path: path, Passphrase: passphrase("secret")}
+	if err := store.Store(site, cred); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), cred.Password) || strings.Contains(string(data), cred.Username) {
+		t.Errorf("credentials stored in plain text:\n%s", data)
+	}
+
+	got, err := (&wp.FileCredentialStore{Path: path, Passphrase: passphrase("secret")}).Get(site)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || *got != cred {
+		t.Errorf("Get = %+v, want %+v", got, cred)
+	}
+
+	_, err = (&wp.FileCredentialStore{Path: path, Passphrase: passphrase("wrong")}).Get(site)
+	if err == nil || !strings.Contains(err.Error(), "パスフレーズが違う") {
+		t.Errorf("Get with a wrong passphrase: err = %v", err)
+	}
+
+	if err := store.Erase(site); err != nil {
+		t.Fatal(err)
+	}
+	got, err = (&wp.FileCredentialStore{Path: path, Passphrase: passphrase("secret")}).Get(site)
+	if err != nil || got != nil {
+		t.Errorf("Get after Erase = %+v, %v; want nil", got, err)
+	}
+}
+
+// testdata/credentials.json は鍵の導出を変更しても既存のファイルを読めることを確かめるためのファイルです
+// （パスフレーズ "correct horse battery staple"、PBKDF2-HMAC-SHA256 1000 回）
+func TestFileCredentialStoreReadsExistingFile(t *testing.T) {
+	store := &wp.FileCredentialStore{Path: "testdata/credentials.json", Passphrase: passphrase("correct horse battery staple")}
+	got, err := store.Get("https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := wp.Credentials{Username: "admin", Password: "abcd efgh ijkl"}
+	if got == nil || *got != want {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,30 @@
+package wp
+
+import "errors"
+
+var (
+	// ErrNotPublished は post_id のない記事を更新・削除しようとした、またはリンク先の記事が公開されていないことを表します
+	ErrNotPublished = errors.New("この記事はまだ投稿されていません")
+	// ErrInvalidArticle は記事ファイルが読み取れない、または形式・メタデータの値が正しくないことを表します。
+	// errors.Is で判定します。
+	ErrInvalidArticle = errors.New("記事ファイルが正しくありません")
+	// ErrAuth は認証情報の取得や認証に失敗したことを表します
+	ErrAuth = errors.New("認証エラー")
+)
+
+// articleError は記事ファイルの誤りです。メッセージは元のエラーのまま、ErrInvalidArticle として判定できます。
+type articleError struct {
+	err error
+}
+
+func (e *articleError) Error() string { return e.err.Error() }
+
+func (e *articleError) Unwrap() []error { return []error{e.err, ErrInvalidArticle} }
+
+// invalidArticle は err を ErrInvalidArticle として判定できるようにします。err が nil の場合は nil を返します。
+func invalidArticle(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &articleError{err}
+}
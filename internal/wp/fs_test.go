@@ -0,0 +1,153 @@
+package wp_test
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+// writableMapFS は WriteFile で fstest.MapFS に書き込むファイルシステムです
+type writableMapFS struct {
+	fstest.MapFS
+}
+
+func (m writableMapFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	m.MapFS[name] = &fstest.MapFile{Data: data, Mode: perm}
+	return nil
+}
+
+func TestReadArticleFS(t *testing.T) {
+	fsys := fstest.MapFS{"posts/1.md": {Data: []byte(profileArticle)}}
+
+	metadata, body, err := wp.ReadArticleFS(fsys, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.Title != "記事" || metadata.PostID != 10 || strings.TrimSpace(body) != "本文" {
+		t.Errorf("ReadArticleFS = %q, %d, %q", metadata.Title, metadata.PostID, body)
+	}
+
+	for _, name := range []string{"posts/2", "../posts/1"} {
+		if _, _, err := wp.ReadArticleFS(fsys, name); !errors.Is(err, wp.ErrInvalidArticle) {
+			t.Errorf("ReadArticleFS(%q): err = %v, want ErrInvalidArticle", name, err)
+		}
+	}
+	fsys["posts/3.md"] = &fstest.MapFile{Data: []byte(`{"Title": "区切りなし"}`)}
+	if _, _, err := wp.ReadArticleFS(fsys, "posts/3"); !errors.Is(err, wp.ErrInvalidArticle) {
+		t.Errorf("ReadArticleFS without ---: err = %v, want ErrInvalidArticle", err)
+	}
+}
+
+func TestUpdateMetadataFS(t *testing.T) {
+	const article = "{\"Title\": \"記事\"}\n---\n本文\n---\n続き\n"
+	fsys := writableMapFS{fstest.MapFS{"a.md": {Data: []byte(article)}}}
+
+	metadata, _, err := wp.ReadArticleFS(fsys, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	metadata.PostID = 5
+	if err := wp.UpdateMetadataFS(fsys, "a", metadata); err != nil {
+		t.Fatal(err)
+	}
+	metadata, body, err := wp.ReadArticleFS(fsys, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.PostID != 5 {
+		t.Errorf("PostID = %d, want 5", metadata.PostID)
+	}
+	// 本文の水平線（---）はそのまま残す
+	if body != "本文\n---\n続き\n" {
+		t.Errorf("body = %q", body)
+	}
+
+	// 書き込みに対応していないファイルシステムでは書き込まずにエラーを返す
+	readOnly := fstest.MapFS{"a.md": {Data: []byte(article)}}
+	if err := wp.UpdateMetadataFS(readOnly, "a", metadata); err == nil {
+		t.Error("UpdateMetadataFS on a read-only FS succeeded")
+	}
+	if got := string(readOnly["a.md"].Data); got != article {
+		t.Errorf("read-only file changed: %q", got)
+	}
+}
+
+func TestUploadImageFS(t *testing.T) {
+	fake := wptest.NewFake()
+	fsys := fstest.MapFS{"photos/y.png": {Data: []byte("\x89PNG image data")}}
+
+	media, err := wp.UploadImageFS(fake, fsys, "photos/y.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.ID == 0 || !strings.HasSuffix(media.URL, "/y.png") {
+		t.Errorf("UploadImageFS = %+v", media)
+	}
+	if _, err := wp.UploadImageFS(fake, fsys, "photos/missing.png"); err == nil {
+		t.Error("UploadImageFS of a missing file succeeded")
+	}
+	if got := fake.Media(); len(got) != 1 {
+		t.Errorf("media library has %d items, want 1: %v", len(got), got)
+	}
+}
+
+func TestListArticlesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"posts_001-050/10.md":    {},
+		"posts_001-050/2.md":     {},
+		"posts_001-050/1.md":     {},
+		"posts_001-050/note.txt": {},
+		"pages/about.md":         {},
+		"_drafts/x.md":           {},
+		".git/y.md":              {},
+		"posts_001-050/_tmp.md":  {},
+		"posts_001-050/.a.md":    {},
+	}
+	names, err := wp.ListArticlesFS(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"pages/about", "posts_001-050/1", "posts_001-050/2", "posts_001-050/10"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("ListArticlesFS = %v, want %v", names, want)
+	}
+}
+
+// pull・new で書き込んだ記事も、メタデータの更新で書き込んだ記事も同じ形式になる
+func TestArticleFormatStable(t *testing.T) {
+	fsys := writableMapFS{fstest.MapFS{}}
+	metadata := wp.ArticleMetadata{Title: "記事"}
+	if err := wp.WriteArticleFS(fsys, "a", metadata, "本文\n"); err != nil {
+		t.Fatal(err)
+	}
+	written := string(fsys.MapFS["a.md"].Data)
+	if !strings.Contains(written, "}\n\n---\n\n本文\n") {
+		t.Errorf("WriteArticleFS wrote %q", written)
+	}
+
+	metadata, body, err := wp.ReadArticleFS(fsys, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "本文\n" {
+		t.Errorf("body = %q, want %q", body, "本文\n")
+	}
+	if err := wp.UpdateMetadataFS(fsys, "a", metadata); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(fsys.MapFS["a.md"].Data); got != written {
+		t.Errorf("UpdateMetadataFS changed the file:\n%q\nwant\n%q", got, written)
+	}
+	if err := wp.WriteArticleFS(fsys, "a", metadata, body); err != nil {
+		t.Fatal(err)
+	}
+	if got := string(fsys.MapFS["a.md"].Data); got != written {
+		t.Errorf("WriteArticleFS of the read body changed the file:\n%q\nwant\n%q", got, written)
+	}
+}
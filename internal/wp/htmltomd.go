@@ -0,0 +1,241 @@
+package wp
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	reHcbBlock    = regexp.MustCompile(`(?s)<div class="hcb_wrap">\s*<pre[^>]*?data-lang="([^"]*)"[^>]*>\s*<code[^>]*>(.*?)</code>\s*</pre>.*?</div>`)
+	rePreBlock    = regexp.MustCompile(`(?s)<pre[^>]*>\s*<code(?: class="language-([^"]*)")?[^>]*>(.*?)</code>\s*</pre>`)
+	reEmbedBlock  = regexp.MustCompile(`(?s)<!-- wp:embed (\{.*?\}) -->.*?<!-- /wp:embed -->`)
+	reBlockMarker = regexp.MustCompile(`<!-- /?wp:[^>]*-->`)
+	reAsideBlock  = regexp.MustCompile(`(?s)<p class="is-style-big_icon_check">TL;DR;<br>\s*(.*?)</p>`)
+	reTableBlock  = regexp.MustCompile(`(?s)<table[^>]*>(.*?)</table>`)
+	reTableRow    = regexp.MustCompile(`(?s)<tr[^>]*>(.*?)</tr>`)
+	reTableCell   = regexp.MustCompile(`(?s)<t[hd][^>]*>(.*?)</t[hd]>`)
+	reListEdge    = regexp.MustCompile(`</?[uo]l[^>]*>|</?p[ >]|<h[1-6]|<table`)
+	reListToken   = regexp.MustCompile(`(?s)</?[uo]l[^>]*>|<li[^>]*>|</li>`)
+	reHeading     = regexp.MustCompile(`(?s)<h([1-6])[^>]*>(.*?)</h[1-6]>`)
+	reImage       = regexp.MustCompile(`<img[^>]*?src="([^"]*)"[^>]*?>`)
+	reImageAlt    = regexp.MustCompile(`alt="([^"]*)"`)
+	reAnchor      = regexp.MustCompile(`(?s)<a[^>]*?href="([^"]*)"[^>]*>(.*?)</a>`)
+	reStrong      = regexp.MustCompile(`(?s)<(?:strong|b)>(.*?)</(?:strong|b)>`)
+	reEmphasis    = regexp.MustCompile(`(?s)<(?:em|i)>(.*?)</(?:em|i)>`)
+	reInlineCode  = regexp.MustCompile(`(?s)<code[^>]*>(.*?)</code>`)
+	reHr          = regexp.MustCompile(`<hr[^>]*>`)
+	reBr          = regexp.MustCompile(`<br\s*/?>`)
+	reParagraph   = regexp.MustCompile(`</?p[^>]*>`)
+	reAnyTag      = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	reManyNewline = regexp.MustCompile(`\n{3,}`)
+)
+
+// ConvertHTMLToMarkdown は投稿本文のHTMLをマークダウンに戻します。
+// ConvertMarkdownToHTML が出力する形式（hcbコードブロック、埋め込みブロック、TL;DR）を優先して扱い、
+// それ以外のタグは可能な範囲で変換し、変換できないものは取り除きます。
+func ConvertHTMLToMarkdown(content string) string {
+	md := strings.ReplaceAll(content, "\r\n", "\n")
+
+	// コードブロックは他の変換の影響を受けないようにプレースホルダへ退避
+	codeBlocks := make(map[string]string)
+	codeCount := 0
+	saveCode := func(lang, code string) string {
+		code = strings.ReplaceAll(code, "&lt;", "<")
+		code = strings.ReplaceAll(code, "&gt;", ">")
+		ph := fmt.Sprintf("MDCODEBLOCK%dX", codeCount)
+		codeBlocks[ph] = "```" + lang + "\n" + strings.Trim(code, "\n") + "\n```"
+		codeCount++
+		return ph
+	}
+	md = reHcbBlock.ReplaceAllStringFunc(md, func(match string) string {
+		parts := reHcbBlock.FindStringSubmatch(match)
+		return saveCode(parts[1], parts[2])
+	})
+	md = rePreBlock.ReplaceAllStringFunc(md, func(match string) string {
+		parts := rePreBlock.FindStringSubmatch(match)
+		return saveCode(parts[1], parts[2])
+	})
+
+	// 埋め込みブロック → URL単独行
+	md = reEmbedBlock.ReplaceAllStringFunc(md, func(match string) string {
+		var attrs struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal([]byte(reEmbedBlock.FindStringSubmatch(match)[1]), &attrs); err != nil || attrs.URL == "" {
+			return match
+		}
+		return attrs.URL
+	})
+	md = reBlockMarker.ReplaceAllString(md, "")
+
+	// TL;DR → aside
+	md = reAsideBlock.ReplaceAllString(md, "\n\n<aside>\n$1\n</aside>\n\n")
+
+	// インライン要素
+	md = reImage.ReplaceAllStringFunc(md, func(match string) string {
+		src := reImage.FindStringSubmatch(match)[1]
+		alt := ""
+		if m := reImageAlt.FindStringSubmatch(match); m != nil {
+			alt = m[1]
+		}
+		return fmt.Sprintf("![%s](%s)", alt, src)
+	})
+	md = reAnchor.ReplaceAllStringFunc(md, func(match string) string {
+		parts := reAnchor.FindStringSubmatch(match)
+		if parts[1] == parts[2] {
+			return parts[1]
+		}
+		return fmt.Sprintf("[%s](%s)", parts[2], parts[1])
+	})
+	md = reStrong.ReplaceAllString(md, "**$1**")
+	md = reEmphasis.ReplaceAllString(md, "*$1*")
+	md = reInlineCode.ReplaceAllString(md, "`$1`")
+
+	// ブロック要素
+	md = reTableBlock.ReplaceAllStringFunc(md, func(match string) string {
+		return "\n\n" + tableToMarkdown(reTableBlock.FindStringSubmatch(match)[1]) + "\n\n"
+	})
+	md = convertLists(md)
+	md = reHeading.ReplaceAllStringFunc(md, func(match string) string {
+		parts := reHeading.FindStringSubmatch(match)
+		level := int(parts[1][0] - '0')
+		return strings.Repeat("#", level) + " " + strings.TrimSpace(parts[2])
+	})
+	md = reHr.ReplaceAllString(md, "\n\n---\n\n")
+	md = reBr.ReplaceAllString(md, "\n")
+	md = reParagraph.ReplaceAllString(md, "\n\n")
+	md = reAnyTag.ReplaceAllStringFunc(md, func(tag string) string {
+		if strings.HasPrefix(tag, "<aside") || strings.HasPrefix(tag, "</aside") {
+			return tag
+		}
+		return ""
+	})
+	md = html.UnescapeString(md)
+
+	// 行末の空白と余分な空行を整理
+	lines := strings.Split(md, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	md = strings.Join(lines, "\n")
+	md = reManyNewline.ReplaceAllString(md, "\n\n")
+	md = strings.TrimSpace(md) + "\n"
+
+	for ph, block := range codeBlocks {
+		md = strings.Replace(md, ph, block, 1)
+	}
+
+	return md
+}
+
+// tableToMarkdown はテーブルの中身をマークダウンのテーブルに変換します
+func tableToMarkdown(table string) string {
+	var rows [][]string
+	for _, row := range reTableRow.FindAllStringSubmatch(table, -1) {
+		var cells []string
+		for _, cell := range reTableCell.FindAllStringSubmatch(row[1], -1) {
+			text := strings.TrimSpace(reAnyTag.ReplaceAllString(cell[1], ""))
+			cells = append(cells, strings.ReplaceAll(text, "\n", " "))
+		}
+		rows = append(rows, cells)
+	}
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	writeRow := func(cells []string) {
+		b.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	writeRow(rows[0])
+	sep := make([]string, len(rows[0]))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	writeRow(sep)
+	for _, row := range rows[1:] {
+		writeRow(row)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// convertLists は最上位の <ul>/<ol> ごとに箇条書きをマークダウンに変換します。
+// 閉じタグが不足している箇条書きは、次の段落・見出しの手前で終わったものとして扱います。
+func convertLists(md string) string {
+	var b strings.Builder
+	depth, start, last := 0, 0, 0
+	closeList := func(end int) {
+		before := md[last:start]
+		b.WriteString(before)
+		if before != "" && !strings.HasSuffix(before, "\n") {
+			b.WriteString("\n")
+		}
+		// processListItems は </ul> の直後の空行を改行1つにするため、ここで空行に戻す
+		b.WriteString(listToMarkdown(md[start:end]) + "\n")
+		last = end
+		depth = 0
+	}
+	for _, loc := range reListEdge.FindAllStringIndex(md, -1) {
+		token := md[loc[0]:loc[1]]
+		switch {
+		case strings.HasPrefix(token, "<ul") || strings.HasPrefix(token, "<ol"):
+			if depth == 0 {
+				start = loc[0]
+			}
+			depth++
+		case strings.HasPrefix(token, "</ul") || strings.HasPrefix(token, "</ol"):
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth == 0 {
+				closeList(loc[1])
+			}
+		default:
+			if depth > 0 {
+				closeList(loc[0])
+			}
+		}
+	}
+	if depth > 0 {
+		closeList(len(md))
+	}
+	b.WriteString(md[last:])
+	return b.String()
+}
+
+// listToMarkdown は入れ子の箇条書きをインデント付きの "- " 形式に変換します。
+// <li> の中に <ul> がある形式と、processListItems が出力する <ul> が兄弟に並ぶ形式の両方を扱います。
+func listToMarkdown(list string) string {
+	var b strings.Builder
+	depth := 0
+	last := 0
+	flush := func(end int) {
+		text := strings.TrimSpace(list[last:end])
+		if text != "" {
+			b.WriteString(strings.ReplaceAll(text, "\n", " "))
+		}
+	}
+	for _, loc := range reListToken.FindAllStringIndex(list, -1) {
+		flush(loc[0])
+		last = loc[1]
+
+		token := list[loc[0]:loc[1]]
+		switch {
+		case strings.HasPrefix(token, "</ul") || strings.HasPrefix(token, "</ol"):
+			depth--
+		case strings.HasPrefix(token, "<ul") || strings.HasPrefix(token, "<ol"):
+			depth++
+		case strings.HasPrefix(token, "<li"):
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			b.WriteString(strings.Repeat("  ", max(depth-1, 0)) + "- ")
+		}
+	}
+	flush(len(list))
+	return b.String()
+}
@@ -0,0 +1,238 @@
+package wp
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var (
+	// reArticleLink は [テキスト](posts_001-050/10.md#見出し) の形式の記事へのリンクです
+	reArticleLink = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s#]+\.md)(#[^)\s]*)?\)`)
+	// reWikiLink は [[posts_001-050/10]] または [[posts_001-050/10|テキスト]] の形式の記事へのリンクです
+	reWikiLink = regexp.MustCompile(`\[\[([^\]|#]+)(#[^\]|]*)?(?:\|([^\]]*))?\]\]`)
+)
+
+// ArticleLink は本文中のほかの記事へのリンク1つです
+type ArticleLink struct {
+	// Target はリンク先の記事名（例: posts_001-050/10）です
+	Target string
+	// Text はリンクのテキストです。[[...]] でテキストを省略した場合は空で、リンク先のタイトルを使います。
+	Text string
+	// Fragment は # で始まる見出しへのリンクです
+	Fragment string
+	// start と end は本文中のリンクの位置です
+	start, end int
+}
+
+// FindArticleLinks は本文（コードを除く）のほかの記事へのリンクを返します。
+// リンク先は記事の置き場所からのパスで、./ または ../ で始まる場合はリンク元の記事 from のディレクトリからのパスです。
+// URL と画像は記事へのリンクとして扱いません。
+func FindArticleLinks(from, body string) ([]ArticleLink, error) {
+	masked := MaskCode(body)
+	var links []ArticleLink
+	for _, m := range reArticleLink.FindAllStringSubmatchIndex(masked, -1) {
+		target := masked[m[6]:m[7]]
+		if masked[m[2]:m[3]] == "!" || strings.Contains(target, "://") {
+			continue
+		}
+		name, err := linkTarget(from, strings.TrimSuffix(target, ".md"))
+		if err != nil {
+			return nil, err
+		}
+		link := ArticleLink{Target: name, Text: body[m[4]:m[5]], start: m[0], end: m[1]}
+		if m[8] >= 0 {
+			link.Fragment = body[m[8]:m[9]]
+		}
+		links = append(links, link)
+	}
+	for _, m := range reWikiLink.FindAllStringSubmatchIndex(masked, -1) {
+		name, err := linkTarget(from, strings.TrimSuffix(strings.TrimSpace(masked[m[2]:m[3]]), ".md"))
+		if err != nil {
+			return nil, err
+		}
+		link := ArticleLink{Target: name, start: m[0], end: m[1]}
+		if m[4] >= 0 {
+			link.Fragment = body[m[4]:m[5]]
+		}
+		if m[6] >= 0 {
+			link.Text = strings.TrimSpace(body[m[6]:m[7]])
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// linkTarget はリンクに書かれたパスを記事名にします
+func linkTarget(from, target string) (string, error) {
+	name := path.Clean(target)
+	if strings.HasPrefix(target, "./") || strings.HasPrefix(target, "../") {
+		name = path.Join(path.Dir(from), target)
+	}
+	if !fs.ValidPath(name) || name == "." {
+		return "", invalidArticle(fmt.Errorf("リンク先の記事名が不正です: %s", target))
+	}
+	return name, nil
+}
+
+// ResolveArticleLinks は記事 from の本文のほかの記事へのリンクを、リンク先の記事の投稿の URL（パーマリンク）に置き換えます。
+// URL はリンク先の記事の post_id の投稿から取得するため、リンク先がまだ投稿されていない、または公開されていない（publish 以外）場合は
+// ErrNotPublished のエラーを返します。
+func ResolveArticleLinks(client API, ws *Workspace, from, body string) (string, error) {
+	links, err := FindArticleLinks(from, body)
+	if err != nil {
+		return "", err
+	}
+	if len(links) == 0 {
+		return body, nil
+	}
+
+	type resolved struct {
+		url, title string
+	}
+	cache := map[string]resolved{}
+	resolve := func(name string) (resolved, error) {
+		if r, ok := cache[name]; ok {
+			return r, nil
+		}
+		url, title, err := articleURL(client, ws, name)
+		if err != nil {
+			return resolved{}, err
+		}
+		r := resolved{url: url, title: title}
+		cache[name] = r
+		return r, nil
+	}
+
+	replacements := make(map[int]ArticleLink, len(links))
+	for _, link := range links {
+		replacements[link.start] = link
+	}
+	var b strings.Builder
+	for i := 0; i < len(body); {
+		link, ok := replacements[i]
+		if !ok {
+			b.WriteByte(body[i])
+			i++
+			continue
+		}
+		r, err := resolve(link.Target)
+		if err != nil {
+			return "", err
+		}
+		text := link.Text
+		if text == "" {
+			text = r.title
+		}
+		fmt.Fprintf(&b, "[%s](%s%s)", text, r.url, link.Fragment)
+		i = link.end
+	}
+	return b.String(), nil
+}
+
+// articleURL は記事 name の置き場所のプロファイルの投稿の URL（パーマリンク）と記事のタイトルを返します
+func articleURL(client API, ws *Workspace, name string) (string, string, error) {
+	metadata, _, err := ws.ReadArticle(name)
+	if err != nil {
+		return "", "", fmt.Errorf("リンク先 %s: %w", name, err)
+	}
+	if metadata.PostID == 0 {
+		return "", "", fmt.Errorf("リンク先 %s: %w", name, ErrNotPublished)
+	}
+	postType, err := ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return "", "", fmt.Errorf("リンク先 %s: %w", name, err)
+	}
+	post, err := client.GetPostOfType(postType.RestBase, metadata.PostID)
+	if err != nil {
+		return "", "", fmt.Errorf("リンク先 %s の投稿取得エラー: %w", name, err)
+	}
+	// 下書き・レビュー待ち・非公開・予約投稿の URL（?p=123）は読者が開けないため、公開済みの投稿だけにリンクする
+	if post.Link == "" || post.Status != "publish" {
+		return "", "", fmt.Errorf("リンク先 %s（投稿ID %d、ステータス %s）: %w", name, metadata.PostID, post.Status, ErrNotPublished)
+	}
+	return post.Link, metadata.Title, nil
+}
+
+// PromoteLinks は記事 from の本文のほかの記事へのリンクについて、コピー元 src のサイトの投稿の URL から
+// コピー先 dst のサイトの投稿の URL への対応を返します。srcWS と dstWS はそれぞれのプロファイルの置き場所です。
+// PromotePost で本文のリンクをコピー先のサイトのものに置き換えるために使います。
+func PromoteLinks(src API, srcWS *Workspace, dst API, dstWS *Workspace, from, body string) (map[string]string, error) {
+	links, err := FindArticleLinks(from, body)
+	if err != nil {
+		return nil, err
+	}
+	urls := make(map[string]string)
+	done := make(map[string]bool)
+	for _, link := range links {
+		if done[link.Target] {
+			continue
+		}
+		done[link.Target] = true
+		srcURL, _, err := articleURL(src, srcWS, link.Target)
+		if err != nil {
+			return nil, err
+		}
+		dstURL, _, err := articleURL(dst, dstWS, link.Target)
+		if err != nil {
+			return nil, err
+		}
+		urls[srcURL] = dstURL
+	}
+	return urls, nil
+}
+
+// SortArticlesByLinks は記事をリンク先の記事が先になる順に並べます。リンクのない記事どうしは元の順のままです。
+// リンクが循環している記事は元の順で最後に並べます。読み込めない記事はリンクのない記事として扱います。
+func SortArticlesByLinks(fsys fs.FS, names []string) []string {
+	index := make(map[string]int, len(names))
+	for i, name := range names {
+		index[name] = i
+	}
+	// deps[i] は names[i] がリンクしている記事（names に含まれるもの）の添字です
+	deps := make([][]int, len(names))
+	for i, name := range names {
+		_, body, err := ReadArticleFS(fsys, name)
+		if err != nil {
+			continue
+		}
+		links, err := FindArticleLinks(name, body)
+		if err != nil {
+			continue
+		}
+		for _, link := range links {
+			if j, ok := index[link.Target]; ok && j != i {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	sorted := make([]string, 0, len(names))
+	done := make([]bool, len(names))
+	for progress := true; progress; {
+		progress = false
+		for i, name := range names {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, j := range deps[i] {
+				ready = ready && done[j]
+			}
+			if ready {
+				sorted = append(sorted, name)
+				done[i], progress = true, true
+				// 先頭から探し直して、元の順をできるだけ保つ
+				break
+			}
+		}
+	}
+	for i, name := range names {
+		if !done[i] {
+			sorted = append(sorted, name)
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,77 @@
+package wp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+const linkingArticle = `{
+  "Title": "リンクのある記事",
+  "Permalink": "linking",
+  "Status": "publish",
+  "Category": [],
+  "Tag": []
+}
+---
+[前の記事](a.md) も参照してください。
+`
+
+// ほかの記事へのリンクのある記事も、投稿後に変更しなければ変更なし・差分なしになる
+func TestLinkedArticleUnchangedAfterPublish(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := wptest.NewWorkspace(map[string]string{"a.md": plainArticle, "b.md": linkingArticle}, nil)
+	publisher := &wp.Publisher{Client: fake, Workspace: ws}
+	for _, name := range []string{"a", "b"} {
+		if _, err := publisher.Create(context.Background(), name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	statuses, err := wp.CollectStatus(fake, ws, []string{"b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statuses[0].Local != wp.LocalUnchanged {
+		t.Errorf("Local = %s, want %s", statuses[0].Local, wp.LocalUnchanged)
+	}
+
+	metadata, body, err := ws.ReadArticle("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, asMarkdown := range []bool{false, true} {
+		diff, err := wp.DiffArticle(fake, ws, "b", metadata, body, asMarkdown)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !diff.Empty() {
+			t.Errorf("diff (asMarkdown %v) of an unchanged article:\n%s", asMarkdown, diff)
+		}
+	}
+}
+
+// 下書きなど公開されていない投稿へのリンクは読者が開けないため、投稿せずにエラーにする
+func TestLinkToUnpublishedArticle(t *testing.T) {
+	for _, status := range []string{"draft", "pending", "private"} {
+		fake := wptest.NewFake()
+		target := strings.Replace(plainArticle, `"Status": "publish"`, `"Status": "`+status+`"`, 1)
+		ws := wptest.NewWorkspace(map[string]string{"a.md": target, "b.md": linkingArticle}, nil)
+		publisher := &wp.Publisher{Client: fake, Workspace: ws}
+		if _, err := publisher.Create(context.Background(), "a"); err != nil {
+			t.Fatal(err)
+		}
+
+		_, err := publisher.Create(context.Background(), "b")
+		if !errors.Is(err, wp.ErrNotPublished) {
+			t.Errorf("%s: err = %v, want ErrNotPublished", status, err)
+		}
+		if posts := fake.Posts("posts"); len(posts) != 1 {
+			t.Errorf("%s: site has %d posts, want 1", status, len(posts))
+		}
+	}
+}
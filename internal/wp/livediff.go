@@ -0,0 +1,149 @@
+package wp
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ArticleDiff はローカルの記事と WordPress 上の投稿の差分です
+type ArticleDiff struct {
+	// Fields はタイトル・スラッグなどの項目の差分、Content は本文の差分（いずれも unified 形式）です
+	Fields  string
+	Content string
+}
+
+// Empty は差分がない場合に true を返します
+func (d *ArticleDiff) Empty() bool {
+	return d.Fields == "" && d.Content == ""
+}
+
+func (d *ArticleDiff) String() string {
+	return d.Fields + d.Content
+}
+
+// DiffArticle は置き場所 ws の記事を WordPress 上の投稿（context=edit）と比較します。
+// ほかの記事へのリンクはリンク先の投稿の URL にし、画像はアップロードせずファイル名で比較します。asMarkdown が true の場合、
+// 本文は WordPress 上の HTML をマークダウンに戻してローカルのマークダウンと比較します。
+func DiffArticle(client API, ws *Workspace, filename string, metadata ArticleMetadata, markdown string, asMarkdown bool) (*ArticleDiff, error) {
+	if metadata.PostID == 0 {
+		return nil, ErrNotPublished
+	}
+
+	postType, err := ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, err
+	}
+	remote, err := client.GetPostOfType(postType.RestBase, metadata.PostID)
+	if err != nil {
+		return nil, fmt.Errorf("投稿取得エラー: %w", err)
+	}
+	// 投稿するときと同じく、ほかの記事へのリンクをリンク先の投稿の URL に置き換えてから比較する
+	markdown, err = ResolveArticleLinks(client, ws, filename, markdown)
+	if err != nil {
+		return nil, fmt.Errorf("記事へのリンクのエラー: %w", err)
+	}
+
+	remoteFields, err := remoteSummary(client, postType, remote)
+	if err != nil {
+		return nil, err
+	}
+	// カテゴリーを指定しない記事には WordPress が既定のカテゴリーを設定するため、既定のカテゴリーと同じとみなす
+	if postType.HasTaxonomy("category") && len(metadata.Category) == 0 {
+		id, err := client.DefaultCategory()
+		if err != nil {
+			return nil, err
+		}
+		metadata.Category, err = categoryNames(client, []int{id})
+		if err != nil {
+			return nil, fmt.Errorf("カテゴリー取得エラー: %w", err)
+		}
+	}
+	localFields := localSummary(postType, metadata, ws.DefaultStatus)
+
+	remoteName := fmt.Sprintf("WordPress（投稿ID %d）", remote.ID)
+	localName := fmt.Sprintf("ローカル（%s）", filename)
+
+	diff := &ArticleDiff{
+		Fields: UnifiedDiff(remoteName, localName, remoteFields, localFields, len(strings.Split(localFields, "\n"))),
+	}
+	if asMarkdown {
+		diff.Content = UnifiedDiff(remoteName, localName,
+			normalizeContent(ConvertHTMLToMarkdown(remote.Content.Raw)), normalizeContent(markdown), 3)
+	} else {
+		diff.Content = UnifiedDiff(remoteName, localName,
+			normalizeForDiff(remote.Content.Raw), normalizeForDiff(ConvertMarkdownToHTML(markdown)), 3)
+	}
+	return diff, nil
+}
+
+// remoteSummary は投稿の項目を比較用のテキストにします
+func remoteSummary(client API, postType *PostType, post *PostResponse) (string, error) {
+	lines := []string{
+		"Title: " + post.Title.Raw,
+		"Slug: " + decodeSlug(post.Slug),
+		"Status: " + post.Status,
+	}
+	if postType.HasTaxonomy("category") {
+		names, err := categoryNames(client, post.Categories)
+		if err != nil {
+			return "", fmt.Errorf("カテゴリー取得エラー: %w", err)
+		}
+		lines = append(lines, "Category: "+joinNames(names))
+	}
+	if postType.HasTaxonomy("post_tag") {
+		names, err := tagNames(client, post.Tags)
+		if err != nil {
+			return "", fmt.Errorf("タグ取得エラー: %w", err)
+		}
+		lines = append(lines, "Tag: "+joinNames(names))
+	}
+
+	image := ""
+	if post.FeaturedMedia != 0 {
+		media, err := GetMedia(client, post.FeaturedMedia)
+		if err != nil {
+			return "", fmt.Errorf("アイキャッチ画像取得エラー: %w", err)
+		}
+		image = imageBaseName(media.URL)
+	}
+	lines = append(lines, "Image: "+image)
+	return strings.Join(lines, "\n"), nil
+}
+
+// localSummary はメタデータの項目を remoteSummary と同じ形式のテキストにします
+func localSummary(postType *PostType, metadata ArticleMetadata, defaultStatus string) string {
+	lines := []string{
+		"Title: " + metadata.Title,
+		"Slug: " + metadata.Permalink,
+		"Status: " + metadata.PostStatus(defaultStatus),
+	}
+	if postType.HasTaxonomy("category") {
+		lines = append(lines, "Category: "+joinNames(metadata.Category))
+	}
+	if postType.HasTaxonomy("post_tag") {
+		lines = append(lines, "Tag: "+joinNames(metadata.Tag))
+	}
+	lines = append(lines, "Image: "+imageBaseName(metadata.Image))
+	return strings.Join(lines, "\n")
+}
+
+// joinNames は大文字小文字と順序の違いを無視できるよう、名前を小文字にして並べ替えて連結します
+func joinNames(names []string) string {
+	sorted := make([]string, len(names))
+	for i, name := range names {
+		sorted[i] = strings.ToLower(name)
+	}
+	sort.Strings(sorted)
+	return strings.Join(sorted, ", ")
+}
+
+// imageBaseName は画像のパスまたはURLからファイル名を取り出し、WordPress が付ける連番やサイズを取り除きます
+func imageBaseName(p string) string {
+	if p == "" {
+		return ""
+	}
+	normalized := normalizeForDiff(`<img src="` + path.Base(p) + `">`)
+	return strings.TrimSuffix(strings.TrimPrefix(normalized, `<img src="`), `">`)
+}
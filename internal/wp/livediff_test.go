@@ -0,0 +1,43 @@
+package wp_test
+
+import (
+	"context"
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+const plainArticle = `{
+  "Title": "カテゴリーのない記事",
+  "Permalink": "no-category",
+  "Status": "publish",
+  "Category": [],
+  "Tag": []
+}
+---
+## はじめに
+
+本文です。
+`
+
+func TestDiffArticleWithoutCategory(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := newSite(t, fake, map[string]string{"a.md": plainArticle})
+	publisher := newPublisher(fake, ws)
+	if _, err := publisher.Create(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, body, err := wp.ReadArticleFS(ws.Articles, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := wp.DiffArticle(publisher.Client, ws, "a", metadata, body, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !diff.Empty() {
+		t.Errorf("diff of an unchanged article:\n%s", diff)
+	}
+}
@@ -0,0 +1,24 @@
+package wp
+
+import (
+	"context"
+	"log/slog"
+)
+
+// discardLogger は Logger が設定されていない場合に使う、何も出力しないロガーです
+var discardLogger = slog.New(discardHandler{})
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+// log はクライアントのロガーを返します。Logger が nil の場合は何も出力しません。
+func (c *Client) log() *slog.Logger {
+	if c.Logger != nil {
+		return c.Logger
+	}
+	return discardLogger
+}
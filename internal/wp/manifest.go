@@ -0,0 +1,102 @@
+package wp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// manifestPath は投稿済みの記事の一覧を記録するファイルを返します。
+// 記事ファイルを削除した後も、どの投稿が残っているかを sync で検出するために使います。プロファイルごとに別のファイルです。
+func manifestPath(profile string) string {
+	if profile != "" {
+		return ".wp-manifest." + profile + ".json"
+	}
+	return ".wp-manifest.json"
+}
+
+// Manifest は記事名（例: posts_001-050/1）と投稿の対応です
+type Manifest struct {
+	Posts map[string]ManifestEntry `json:"posts"`
+
+	fsys    fs.FS
+	profile string
+}
+
+// ManifestEntry は投稿済みの記事1つの情報です
+type ManifestEntry struct {
+	PostID int    `json:"post_id"`
+	Type   string `json:"type,omitempty"`
+}
+
+// LoadManifest はマニフェストを読み込みます。ファイルがない場合は空のマニフェストを返します。
+func LoadManifest() (*Manifest, error) {
+	return DefaultWorkspace.LoadManifest()
+}
+
+// LoadManifest は置き場所のプロファイルのマニフェストを読み込みます
+func (ws *Workspace) LoadManifest() (*Manifest, error) {
+	return LoadManifestFS(ws.Articles, ws.Profile)
+}
+
+// LoadManifestFS は記事のファイルシステムからプロファイルのマニフェストを読み込みます。空のプロファイルは既定のプロファイルです。
+func LoadManifestFS(fsys fs.FS, profile string) (*Manifest, error) {
+	manifest := &Manifest{Posts: make(map[string]ManifestEntry), fsys: fsys, profile: profile}
+	data, err := fs.ReadFile(fsys, manifestPath(profile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("マニフェスト読み取りエラー: %w", err)
+	}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("マニフェストのJSONパースエラー: %w", err)
+	}
+	if manifest.Posts == nil {
+		manifest.Posts = make(map[string]ManifestEntry)
+	}
+	return manifest, nil
+}
+
+// Save はマニフェストを書き込みます
+func (m *Manifest) Save() error {
+	data, err := json.MarshalIndent(m, "", "    ")
+	if err != nil {
+		return fmt.Errorf("マニフェストのJSON変換エラー: %w", err)
+	}
+	if err := writeFile(m.fsys, manifestPath(m.profile), append(data, '\n')); err != nil {
+		return fmt.Errorf("マニフェスト書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// RecordPost は記事の投稿IDをマニフェストに記録します。postID が 0 の場合は記録を削除します。
+func RecordPost(name string, postID int, postType string) error {
+	return DefaultWorkspace.RecordPost(name, postID, postType)
+}
+
+// RecordPost は置き場所のプロファイルのマニフェストに投稿IDを記録します
+func (ws *Workspace) RecordPost(name string, postID int, postType string) error {
+	return RecordPostFS(ws.Articles, ws.Profile, name, postID, postType)
+}
+
+// RecordPostFS は記事のファイルシステムのプロファイルのマニフェストに投稿IDを記録します
+func RecordPostFS(fsys fs.FS, profile, name string, postID int, postType string) error {
+	manifest, err := LoadManifestFS(fsys, profile)
+	if err != nil {
+		return err
+	}
+	current, ok := manifest.Posts[name]
+	switch {
+	case postID == 0 && !ok:
+		return nil
+	case postID == 0:
+		delete(manifest.Posts, name)
+	case ok && current.PostID == postID && current.Type == postType:
+		return nil
+	default:
+		manifest.Posts[name] = ManifestEntry{PostID: postID, Type: postType}
+	}
+	return manifest.Save()
+}
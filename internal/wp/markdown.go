@@ -4,31 +4,69 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 )
 
+// 本文のコードブロック（```）とインラインコード（`）です。ConvertMarkdownToHTML と MaskCode で同じ規則を使います。
+var (
+	reMdCodeBlock  = regexp.MustCompile("(?s)```(.*?)\n(.*?)```")
+	reMdInlineCode = regexp.MustCompile("`([^`]+)`")
+)
+
+// MaskCode は本文のコードブロックとインラインコードを空白に置き換えます。行の数と位置は変えません。
+// ConvertMarkdownToHTML がコードとして扱う部分を除いて、文章やマークダウンの記法だけを検査するのに使います。
+func MaskCode(markdown string) string {
+	mask := func(code string) string {
+		var b strings.Builder
+		for _, r := range code {
+			if r == '\n' {
+				b.WriteByte('\n')
+			} else {
+				b.WriteString(strings.Repeat(" ", utf8.RuneLen(r)))
+			}
+		}
+		return b.String()
+	}
+	markdown = reMdCodeBlock.ReplaceAllStringFunc(markdown, mask)
+	return reMdInlineCode.ReplaceAllStringFunc(markdown, mask)
+}
+
 func ReadArticleFromMd(filename string) (ArticleMetadata, string, error) {
-	content, err := os.ReadFile(fmt.Sprintf("internal/articles/%s.md", filename))
+	return DefaultWorkspace.ReadArticle(filename)
+}
+
+// ReadArticleFS はファイルシステムから記事名（例: posts_001-050/1）の記事を、既定のプロファイルの投稿IDと同期状態で読み込みます
+func ReadArticleFS(fsys fs.FS, name string) (ArticleMetadata, string, error) {
+	p, err := articlePath(name)
 	if err != nil {
-		return ArticleMetadata{}, "", fmt.Errorf("ファイル読み取りエラー: %v", err)
+		return ArticleMetadata{}, "", invalidArticle(err)
 	}
+	content, err := fs.ReadFile(fsys, p)
+	if err != nil {
+		return ArticleMetadata{}, "", invalidArticle(fmt.Errorf("ファイル読み取りエラー: %w", err))
+	}
+	return ParseArticle(content)
+}
 
+// ParseArticle は記事ファイルの内容をメタデータと本文に分けます
+func ParseArticle(content []byte) (ArticleMetadata, string, error) {
 	// JSONメタデータと本文を分離
 	parts := bytes.SplitN(content, []byte("\n---\n"), 2)
 	if len(parts) != 2 {
-		return ArticleMetadata{}, "", fmt.Errorf("ファイルフォーマットが不正です。JSONメタデータと本文を'---'で区切ってください")
+		return ArticleMetadata{}, "", invalidArticle(fmt.Errorf("ファイルフォーマットが不正です。JSONメタデータと本文を'---'で区切ってください"))
 	}
 
 	// JSONメタデータをパース
 	var metadata ArticleMetadata
 	if err := json.Unmarshal(parts[0], &metadata); err != nil {
-		return ArticleMetadata{}, "", fmt.Errorf("メタデータのJSONパースエラー: %v", err)
+		return ArticleMetadata{}, "", invalidArticle(fmt.Errorf("メタデータのJSONパースエラー: %w", err))
 	}
 
-	// 本文を取得
-	return metadata, string(parts[1]), nil
+	// 本文を取得。区切りの後の空行は書き込み時に articleSeparator で付け直すため、本文に含めない
+	return metadata, strings.TrimPrefix(string(parts[1]), "\n"), nil
 }
 
 // 箇条書き変換（インデント対応）
@@ -102,9 +140,8 @@ func ConvertMarkdownToHTML(markdown string) string {
 	blockCount := 0
 
 	// ``` コードブロック → プレースホルダ
-	html = regexp.MustCompile("(?s)```(.*?)\n(.*?)```").ReplaceAllStringFunc(html, func(match string) string {
-		re := regexp.MustCompile("(?s)```(.*?)\n(.*?)```")
-		parts := re.FindStringSubmatch(match)
+	html = reMdCodeBlock.ReplaceAllStringFunc(html, func(match string) string {
+		parts := reMdCodeBlock.FindStringSubmatch(match)
 		if len(parts) == 3 {
 			lang := strings.TrimSpace(parts[1])
 			code := strings.TrimSpace(parts[2])
@@ -144,8 +181,8 @@ func ConvertMarkdownToHTML(markdown string) string {
 	inlineCodeBlocks := make(map[string]string)
 	inlinePlaceholder := "INLINE_CODE_PLACEHOLDER_%d"
 	inlineCount := 0
-	html = regexp.MustCompile("`([^`]+)`").ReplaceAllStringFunc(html, func(match string) string {
-		content := regexp.MustCompile("`([^`]+)`").FindStringSubmatch(match)[1]
+	html = reMdInlineCode.ReplaceAllStringFunc(html, func(match string) string {
+		content := reMdInlineCode.FindStringSubmatch(match)[1]
 		content = strings.ReplaceAll(content, "<", "&lt;")
 		content = strings.ReplaceAll(content, ">", "&gt;")
 		currentPlaceholder := fmt.Sprintf(inlinePlaceholder, inlineCount)
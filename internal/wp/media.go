@@ -2,98 +2,258 @@ package wp
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
+	"io/fs"
 	"mime/multipart"
 	"net/http"
-	"os"
-	"path/filepath"
+	"net/url"
+	"path"
 	"regexp"
+	"strings"
 )
 
-func UploadFeaturedImage(client *Client, imagePath string) (int, error) {
-	imageData, err := os.ReadFile(fmt.Sprintf("internal/images/%s", imagePath))
+func UploadFeaturedImage(client API, imagePath string) (int, error) {
+	media, err := UploadImage(client, imagePath)
 	if err != nil {
-		return 0, fmt.Errorf("画像ファイル読み取りエラー: %v", err)
+		return 0, err
+	}
+	return media.ID, nil
+}
+
+// UploadImage は画像をアップロードします。
+// 同じ内容の画像をアップロード済みの場合はキャッシュされたメディアを返します。
+func UploadImage(client API, imagePath string) (*MediaResponse, error) {
+	return UploadImageFS(client, DefaultWorkspace.Images, imagePath)
+}
+
+// UploadImageFS はファイルシステムの画像をアップロードします
+func UploadImageFS(client API, fsys fs.FS, imagePath string) (*MediaResponse, error) {
+	upload, err := uploadImageFile(client, fsys, imagePath)
+	if err != nil {
+		return nil, err
+	}
+	return &upload.Media, nil
+}
+
+// ImageUpload は画像1つのアップロード結果です
+type ImageUpload struct {
+	// Path は画像の置き場所からのパスです
+	Path  string        `json:"path"`
+	Media MediaResponse `json:"media"`
+	// Uploaded は新しくアップロードした場合に true、アップロード済みの画像を再利用した場合に false です
+	Uploaded bool `json:"uploaded"`
+}
+
+func uploadImageFile(client API, fsys fs.FS, imagePath string) (*ImageUpload, error) {
+	imageData, err := fs.ReadFile(fsys, path.Clean(imagePath))
+	if err != nil {
+		return nil, fmt.Errorf("画像ファイル読み取りエラー: %w", err)
+	}
+	media, uploaded, err := uploadImageData(client, path.Base(imagePath), imageData)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageUpload{Path: imagePath, Media: *media, Uploaded: uploaded}, nil
+}
+
+// UploadImageData は画像のデータをファイル名 name でアップロードします
+func UploadImageData(client API, name string, imageData []byte) (*MediaResponse, error) {
+	media, _, err := uploadImageData(client, name, imageData)
+	return media, err
+}
+
+// uploadImageData は画像をアップロードし、アップロードしたかどうかも返します。
+// キャッシュにない場合もメディアライブラリに同じ内容の画像があればアップロードせずに再利用します。
+func uploadImageData(client API, name string, imageData []byte) (*MediaResponse, bool, error) {
+	hash := hashImage(imageData)
+	cache := client.SharedCache()
+	if media, ok := cache.lookupMedia(hash); ok {
+		return &media, false, nil
+	}
+	if media := findUploadedMedia(client, name, hash); media != nil {
+		cache.addMedia(hash, *media)
+		return media, false, nil
+	}
+
+	media, err := client.UploadMedia(name, imageData)
+	if err != nil {
+		return nil, false, err
+	}
+
+	cache.addMedia(hash, *media)
+	return media, true, nil
+}
+
+func hashImage(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// reUploadSuffix は WordPress がアップロード時にファイル名に付ける連番（-1）と大きな画像の縮小（-scaled）です
+var reUploadSuffix = regexp.MustCompile(`^(-\d+)?(-scaled)?$`)
+
+// findUploadedMedia はファイル名 name でメディアライブラリを検索し、内容のハッシュが hash の画像を返します。
+// 別の実行でアップロードした画像をもう一度アップロードして、WordPress が連番を付けた複製（y-1.png）を作らないために使います。
+// 検索できない場合（メディアの一覧の権限がないなど）は nil を返し、アップロードします。
+func findUploadedMedia(client API, name, hash string) *MediaResponse {
+	ext := path.Ext(name)
+	stem := mediaStem(strings.TrimSuffix(path.Base(name), ext))
+	if stem == "" {
+		return nil
+	}
+	candidates, err := client.SearchMedia(stem)
+	if err != nil {
+		return nil
+	}
+	for _, media := range candidates {
+		file := media.URL
+		if u, err := url.Parse(media.URL); err == nil {
+			file = u.Path
+		}
+		file = path.Base(file)
+		remoteExt := path.Ext(file)
+		suffix, ok := strings.CutPrefix(mediaStem(strings.TrimSuffix(file, remoteExt)), stem)
+		if !ok || !reUploadSuffix.MatchString(suffix) || !strings.EqualFold(remoteExt, ext) {
+			continue
+		}
+		data, err := client.DownloadMedia(media)
+		if err != nil {
+			continue
+		}
+		if hashImage(data) == hash {
+			return &media
+		}
 	}
+	return nil
+}
 
+// mediaStem は WordPress がファイル名を整えるのと同じく、小文字にして空白をハイフンにします
+func mediaStem(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), "-"))
+}
+
+// UploadMedia は画像をファイル名 name でメディアライブラリにアップロードします
+func (c *Client) UploadMedia(name string, imageData []byte) (*MediaResponse, error) {
 	// マルチパートフォームデータを作成
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
-	part, err := writer.CreateFormFile("file", filepath.Base(imagePath))
+	part, err := writer.CreateFormFile("file", name)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	part.Write(imageData)
 	writer.Close()
 
 	// メディアアップロードのリクエストを作成
-	url := client.BaseURL + "/wp-json/wp/v2/media"
+	url := c.BaseURL + "/wp-json/wp/v2/media"
 	req, err := http.NewRequest("POST", url, body)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+client.BasicAuth)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
 	// リクエストを送信
-	resp, err := client.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	// レスポンスを処理
 	var mediaResp MediaResponse
-	if err := client.decodeResponse(resp, &mediaResp); err != nil {
-		return 0, err
+	if err := c.decodeResponse(resp, &mediaResp); err != nil {
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusCreated {
-		return 0, fmt.Errorf("画像アップロードエラー: %d", resp.StatusCode)
+		return nil, fmt.Errorf("画像アップロードエラー: %d", resp.StatusCode)
 	}
 
-	return mediaResp.ID, nil
+	return &mediaResp, nil
 }
 
-func ExtractAndUploadImages(client *Client, content string) (string, error) {
-	re := regexp.MustCompile(`!\[([^\]]*)\]\(internal/images/([^)]+)\)`)
+func ExtractAndUploadImages(client API, content string) (string, error) {
+	return ExtractAndUploadImagesFS(client, DefaultWorkspace, content)
+}
 
+// ExtractAndUploadImagesFS は本文で ws.ImagePrefix 以下を参照している画像をアップロードし、URL に置き換えます。
+// アップロードできなかった画像の参照はそのまま残します。
+func ExtractAndUploadImagesFS(client API, ws *Workspace, content string) (string, error) {
+	result, _, _ := UploadContentImages(client, ws, content)
+	return result, nil
+}
+
+// UploadContentImages は本文で ws.ImagePrefix 以下を参照している画像をアップロードし、URL に置き換えます。
+// 画像ごとのアップロード結果と、アップロードできずに参照をそのまま残した画像のエラーを返します。
+func UploadContentImages(client API, ws *Workspace, content string) (string, []ImageUpload, []error) {
+	re := regexp.MustCompile(`!\[([^\]]*)\]\(` + regexp.QuoteMeta(ws.ImagePrefix+"/") + `([^)]+)\)`)
+
+	var uploads []ImageUpload
+	var errs []error
 	result := re.ReplaceAllStringFunc(content, func(match string) string {
 		matches := re.FindStringSubmatch(match)
 		if len(matches) >= 3 {
 			alt := matches[1]
 			imagePath := matches[2]
 
-			mediaID, err := UploadFeaturedImage(client, imagePath)
+			// アップロードのレスポンスに含まれるURLをそのまま使う
+			upload, err := uploadImageFile(client, ws.Images, imagePath)
 			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", imagePath, err))
 				return match // エラーの場合は元のまま
 			}
+			uploads = append(uploads, *upload)
 
-			// WordPressメディアのURLを取得
-			url := fmt.Sprintf("%s/wp-json/wp/v2/media/%d", client.BaseURL, mediaID)
-			req, err := http.NewRequest("GET", url, nil)
-			if err != nil {
-				return match
-			}
-
-			req.Header.Set("Authorization", "Basic "+client.BasicAuth)
-			resp, err := client.HTTPClient.Do(req)
-			if err != nil {
-				return match
-			}
-			defer resp.Body.Close()
-
-			var mediaResp MediaResponse
-			if err := client.decodeResponse(resp, &mediaResp); err != nil {
-				return match
-			}
-
-			return fmt.Sprintf("![%s](%s)", alt, mediaResp.URL)
+			return fmt.Sprintf("![%s](%s)", alt, upload.Media.URL)
 		}
 		return match
 	})
 
-	return result, nil
+	return result, uploads, errs
+}
+
+// GetMedia はメディアIDからメディアの情報を取得します
+func GetMedia(client API, id int) (*MediaResponse, error) {
+	return client.GetMedia(id)
+}
+
+// SearchMedia はメディアライブラリの画像をタイトルで検索します
+func (c *Client) SearchMedia(search string) ([]MediaResponse, error) {
+	return getAllPages[MediaResponse](c, "/wp-json/wp/v2/media?media_type=image&search="+url.QueryEscape(search))
+}
+
+// DownloadMedia はメディアのファイルを source_url から取得します。
+// アップロード先が別のホスト（CDN など）の場合があるため、認証情報はサイトと同じホストの場合だけ付けます。
+func (c *Client) DownloadMedia(media MediaResponse) ([]byte, error) {
+	req, err := http.NewRequestWithContext(c.context(), "GET", media.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	var resp *http.Response
+	if sameHost(media.URL, c.BaseURL) {
+		resp, err = c.do(req)
+	} else {
+		resp, err = c.HTTPClient.Do(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("画像取得エラー: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
 }
 
+// GetMedia はメディアIDからメディアの情報を取得します
+func (c *Client) GetMedia(id int) (*MediaResponse, error) {
+	var media MediaResponse
+	if err := getJSON(c, fmt.Sprintf("/wp-json/wp/v2/media/%d", id), &media); err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
@@ -3,23 +3,151 @@ package wp
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
+	"io/fs"
 )
 
+// Status として指定できる値
+var postStatuses = []string{"publish", "draft", "pending", "private"}
+
+// 投稿フォーマットとして指定できる値
+var postFormats = []string{"standard", "aside", "chat", "gallery", "link", "image", "quote", "status", "video", "audio"}
+
+// Validate はメタデータの値が WordPress で受け付けられる形式かを検証します
+func (m ArticleMetadata) Validate() error {
+	var errs []error
+	if m.Status == "trash" {
+		errs = append(errs, fmt.Errorf("この記事の投稿はゴミ箱に移されています。投稿し直す場合は Status を変更してください"))
+	} else if m.Status != "" && !contains(postStatuses, m.Status) {
+		errs = append(errs, fmt.Errorf("Status は %v のいずれかを指定してください: %s", postStatuses, m.Status))
+	}
+	if m.CommentStatus != "" && m.CommentStatus != "open" && m.CommentStatus != "closed" {
+		errs = append(errs, fmt.Errorf("CommentStatus は open または closed を指定してください: %s", m.CommentStatus))
+	}
+	if m.PingStatus != "" && m.PingStatus != "open" && m.PingStatus != "closed" {
+		errs = append(errs, fmt.Errorf("PingStatus は open または closed を指定してください: %s", m.PingStatus))
+	}
+	if m.Format != "" && !contains(postFormats, m.Format) {
+		errs = append(errs, fmt.Errorf("Format は %v のいずれかを指定してください: %s", postFormats, m.Format))
+	}
+	isPost := m.Type == "" || m.Type == "post" || m.Type == "posts"
+	if m.Sticky != nil && !isPost {
+		errs = append(errs, fmt.Errorf("Sticky は投稿（post）でのみ指定できます"))
+	}
+	if m.Format != "" && !isPost {
+		errs = append(errs, fmt.Errorf("Format は投稿（post）でのみ指定できます"))
+	}
+	return invalidArticle(errors.Join(errs...))
+}
+
+// PostStatus は投稿時のステータスを返します。Status がない場合は defaultStatus、それも空の場合は publish です。
+func (m ArticleMetadata) PostStatus(defaultStatus string) string {
+	switch {
+	case m.Status != "":
+		return m.Status
+	case defaultStatus != "":
+		return defaultStatus
+	}
+	return "publish"
+}
+
+// StateFor はプロファイルの投稿IDと同期状態を返します。空のプロファイルは既定のプロファイルです。
+func (m ArticleMetadata) StateFor(profile string) ProfileState {
+	switch profile {
+	case m.profile:
+		return ProfileState{PostID: m.PostID, Sync: m.Sync}
+	case "":
+		return m.defaultState
+	}
+	return m.Profiles[profile]
+}
+
+// SetStateFor はプロファイルの投稿IDと同期状態を設定します
+func (m *ArticleMetadata) SetStateFor(profile string, state ProfileState) {
+	switch profile {
+	case m.profile:
+		m.PostID, m.Sync = state.PostID, state.Sync
+		return
+	case "":
+		m.defaultState = state
+		return
+	}
+
+	m.Profiles = withProfileState(m.Profiles, profile, state)
+}
+
+// withProfileState は profiles を複製してプロファイルの状態を設定します。空の状態は取り除きます。
+func withProfileState(profiles map[string]ProfileState, profile string, state ProfileState) map[string]ProfileState {
+	result := make(map[string]ProfileState, len(profiles)+1)
+	for name, s := range profiles {
+		result[name] = s
+	}
+	if state == (ProfileState{}) {
+		delete(result, profile)
+	} else {
+		result[profile] = state
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
+
+// WithProfile は PostID と Sync をプロファイルの投稿IDと同期状態に入れ替えたメタデータを返します。
+// 空のプロファイルは既定のプロファイルです。ファイルに書き込むときは元の形に戻します。
+func (m ArticleMetadata) WithProfile(profile string) ArticleMetadata {
+	m = m.fileForm()
+	if profile == "" {
+		return m
+	}
+	m.profile = profile
+	m.defaultState = ProfileState{PostID: m.PostID, Sync: m.Sync}
+	state := m.Profiles[profile]
+	m.PostID, m.Sync = state.PostID, state.Sync
+	return m
+}
+
+// fileForm は WithProfile で入れ替えた PostID と Sync を、ファイルに書き込む形に戻します
+func (m ArticleMetadata) fileForm() ArticleMetadata {
+	if m.profile == "" {
+		return m
+	}
+	m.Profiles = withProfileState(m.Profiles, m.profile, ProfileState{PostID: m.PostID, Sync: m.Sync})
+	m.PostID, m.Sync = m.defaultState.PostID, m.defaultState.Sync
+	m.profile, m.defaultState = "", ProfileState{}
+	return m
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // UpdateMetadata はマークダウンファイルのメタデータを更新します
 func UpdateMetadata(filename string, metadata ArticleMetadata) error {
-	// .md拡張子を追加
-	mdFilename := filename + ".md"
+	return UpdateMetadataFS(DefaultWorkspace.Articles, filename, metadata)
+}
+
+// UpdateMetadataFS は本文を変えずに記事のメタデータを書き換えます。fsys は WriteFileFS である必要があります。
+func UpdateMetadataFS(fsys fs.FS, filename string, metadata ArticleMetadata) error {
+	mdFilename, err := articlePath(filename)
+	if err != nil {
+		return err
+	}
 
 	// ファイルの内容を読み込む
-	content, err := os.ReadFile(fmt.Sprintf("internal/articles/%s", mdFilename))
+	content, err := fs.ReadFile(fsys, mdFilename)
 	if err != nil {
 		return fmt.Errorf("ファイル読み取りエラー: %w", err)
 	}
 
 	// 新しいメタデータをJSON形式に変換（プリティプリント）
-	newMetadata, err := json.MarshalIndent(metadata, "", "    ")
+	newMetadata, err := json.MarshalIndent(metadata.fileForm(), "", "    ")
 	if err != nil {
 		return fmt.Errorf("メタデータのJSONパースエラー: %w", err)
 	}
@@ -31,20 +159,61 @@ func UpdateMetadata(filename string, metadata ArticleMetadata) error {
 		return fmt.Errorf("メタデータセクションが見つかりません。ファイル形式を確認してください: %s", mdFilename)
 	}
 
-	// 本文部分を結合（複数の---がある場合に対応）
-	body := bytes.Join(parts[1:], []byte("\n---\n"))
+	// 本文部分を結合（複数の---がある場合に対応）。区切りの後の空行は articleSeparator で書き直す
+	body := bytes.TrimPrefix(bytes.Join(parts[1:], []byte("\n---\n")), []byte("\n"))
 
 	// 新しいファイルの内容を構築
 	var newContent bytes.Buffer
 	newContent.Write(newMetadata)
-	newContent.WriteString("\n\n---\n")
+	newContent.WriteString(articleSeparator)
 	newContent.Write(body)
 
 	// ファイルに書き込む
-	err = os.WriteFile(fmt.Sprintf("internal/articles/%s", mdFilename), newContent.Bytes(), 0644)
+	if err := writeFile(fsys, mdFilename, newContent.Bytes()); err != nil {
+		return fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+
+	return nil
+}
+
+// WriteArticleToMd はメタデータと本文からマークダウンファイルを書き込みます
+func WriteArticleToMd(filename string, metadata ArticleMetadata, body string) error {
+	return WriteArticleFS(DefaultWorkspace.Articles, filename, metadata, body)
+}
+
+// WriteArticleFS はメタデータと本文から記事ファイルを書き込みます。fsys は WriteFileFS である必要があります。
+func WriteArticleFS(fsys fs.FS, filename string, metadata ArticleMetadata, body string) error {
+	mdFilename, err := articlePath(filename)
+	if err != nil {
+		return err
+	}
+
+	newContent, err := formatArticle(metadata, body)
 	if err != nil {
+		return err
+	}
+
+	if err := writeFile(fsys, mdFilename, newContent); err != nil {
 		return fmt.Errorf("ファイル書き込みエラー: %w", err)
 	}
 
 	return nil
 }
+
+// articleSeparator は記事ファイルに書き込むときのメタデータと本文の区切りです。
+// 読み込みでは "\n---\n" で分け、区切りの後の空行は本文に含めません。
+const articleSeparator = "\n\n---\n\n"
+
+// formatArticle はメタデータと本文を記事ファイルの内容にします
+func formatArticle(metadata ArticleMetadata, body string) ([]byte, error) {
+	newMetadata, err := json.MarshalIndent(metadata.fileForm(), "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("メタデータのJSONパースエラー: %w", err)
+	}
+
+	var newContent bytes.Buffer
+	newContent.Write(newMetadata)
+	newContent.WriteString(articleSeparator)
+	newContent.WriteString(body)
+	return newContent.Bytes(), nil
+}
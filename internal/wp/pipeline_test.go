@@ -0,0 +1,188 @@
+package wp_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+const taggedArticle = `{
+  "Title": "こんにちは",
+  "Permalink": "hello",
+  "Category": [],
+  "Tag": ["Go"]
+}
+---
+## はじめに
+
+本文です。
+`
+
+// appendBody は記事の本文に text を追記します
+func appendBody(t *testing.T, ws *wp.Workspace, name, text string) {
+	t.Helper()
+	metadata, body, err := wp.ReadArticleFS(ws.Articles, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wp.WriteArticleFS(ws.Articles, name, metadata, body+text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPublisherCreateAndUpdate(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := wptest.NewWorkspace(map[string]string{"posts/1.md": taggedArticle}, nil)
+	publisher := &wp.Publisher{Client: fake, Workspace: ws}
+	ctx := context.Background()
+
+	result, err := publisher.Create(ctx, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, ok := fake.Post(result.PostID)
+	if !ok {
+		t.Fatalf("post %d not found", result.PostID)
+	}
+	if post.Title.Raw != "こんにちは" || post.Slug != "hello" || post.Status != "publish" {
+		t.Errorf("post = %q %q %q", post.Title.Raw, post.Slug, post.Status)
+	}
+	if !strings.Contains(post.Content.Raw, "<h2>はじめに</h2>") {
+		t.Errorf("content = %q", post.Content.Raw)
+	}
+	if len(result.CreatedTags) != 1 || !reflect.DeepEqual(post.Tags, []int{result.CreatedTags[0].ID}) {
+		t.Errorf("tags = %v, created = %v", post.Tags, result.CreatedTags)
+	}
+
+	metadata, _, err := wp.ReadArticleFS(ws.Articles, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.PostID != result.PostID || metadata.Sync == nil {
+		t.Errorf("post_id = %d, sync = %v; want %d and a sync state", metadata.PostID, metadata.Sync, result.PostID)
+	}
+	manifest, err := ws.LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Posts["posts/1"].PostID != result.PostID {
+		t.Errorf("manifest = %v", manifest.Posts)
+	}
+
+	appendBody(t, ws, "posts/1", "\n追記しました。\n")
+	result, err = publisher.Update(ctx, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Field != "content" {
+		t.Errorf("Changes = %v, want content only", result.Changes)
+	}
+	post, _ = fake.Post(result.PostID)
+	if !strings.Contains(post.Content.Raw, "追記しました。") {
+		t.Errorf("content = %q", post.Content.Raw)
+	}
+}
+
+func TestPublisherConflict(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := wptest.NewWorkspace(map[string]string{"posts/1.md": taggedArticle}, nil)
+	publisher := &wp.Publisher{Client: fake, Workspace: ws}
+	ctx := context.Background()
+
+	created, err := publisher.Create(ctx, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fake.UpdatePostFields("posts", created.PostID, map[string]interface{}{"content": "<p>WordPress で編集しました。</p>"}); err != nil {
+		t.Fatal(err)
+	}
+	appendBody(t, ws, "posts/1", "\nローカルで編集しました。\n")
+
+	_, err = publisher.Update(ctx, "posts/1")
+	var conflict *wp.ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("err = %v, want ConflictError", err)
+	}
+	if conflict.Base == nil {
+		t.Error("the last published revision was not found")
+	}
+	diff := conflict.Diff()
+	if !strings.Contains(diff, "WordPress で編集しました。") || !strings.Contains(diff, "ローカルで編集しました。") {
+		t.Errorf("Diff =\n%s", diff)
+	}
+
+	publisher.Resolution = wp.ResolveTheirs
+	result, err := publisher.Update(ctx, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Pulled {
+		t.Error("Pulled = false, want true")
+	}
+	_, body, err := wp.ReadArticleFS(ws.Articles, "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body, "WordPress で編集しました。") {
+		t.Errorf("body = %q", body)
+	}
+}
+
+// タグが100件を超えるサイトでは、2ページ目以降のタグも既存のタグとして使う
+func TestPublisherFindsTagOnLaterPage(t *testing.T) {
+	fake := wptest.NewFake()
+	var goTag *wp.Tag
+	for i := 0; i < 150; i++ {
+		tag, err := fake.CreateTag(fmt.Sprintf("tag%03d", i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		goTag = tag
+	}
+	article := strings.Replace(taggedArticle, `"Go"`, `"`+goTag.Name+`"`, 1)
+	ws := newSite(t, fake, map[string]string{"posts/1.md": article})
+
+	result, err := newPublisher(fake, ws).Create(context.Background(), "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, _ := fake.Post(result.PostID)
+	if !reflect.DeepEqual(post.Tags, []int{goTag.ID}) || len(result.CreatedTags) != 0 {
+		t.Errorf("tags = %v, created = %v; want [%d] and none created", post.Tags, result.CreatedTags, goTag.ID)
+	}
+	if tags, _ := fake.Tags(); len(tags) != 150 {
+		t.Errorf("site has %d tags, want 150", len(tags))
+	}
+}
+
+// 取得済みのタグ一覧にないタグを別のプロセスが作成していた場合は、term_exists のタームを使う
+func TestPublisherUsesExistingTermOnTermExists(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := newSite(t, fake, map[string]string{"posts/1.md": taggedArticle})
+	publisher := newPublisher(fake, ws)
+	if _, err := publisher.Client.Tags(); err != nil {
+		t.Fatal(err)
+	}
+	goTag, err := fake.CreateTag("Go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := publisher.Create(context.Background(), "posts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	post, _ := fake.Post(result.PostID)
+	if !reflect.DeepEqual(post.Tags, []int{goTag.ID}) || len(result.CreatedTags) != 0 {
+		t.Errorf("tags = %v, created = %v; want [%d] and none created", post.Tags, result.CreatedTags, goTag.ID)
+	}
+	if tags, _ := fake.Tags(); len(tags) != 1 {
+		t.Errorf("site has %d tags, want 1", len(tags))
+	}
+}
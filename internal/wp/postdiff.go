@@ -0,0 +1,194 @@
+package wp
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldChange はサーバー上の投稿とローカルの投稿リクエストで値が異なるフィールドです
+type FieldChange struct {
+	// Field は REST API のフィールド名です（meta のキーは "meta.キー" の形式）
+	Field  string      `json:"field"`
+	Remote interface{} `json:"remote"`
+	Local  interface{} `json:"local"`
+}
+
+// DiffPost は投稿リクエストを現在の投稿（context=edit で取得したもの）と比較し、変更のあるフィールドを返します。
+// ローカルで値が指定されていないフィールド（空文字、0、空のスライス、nil）は変更なしとして扱い、
+// WordPress 側で設定された値を上書きしません。
+// uploads は本文の画像のアップロード結果で、WordPress がその画像に付けたサイズ（-300x200）だけを無視して本文を比較します。
+func DiffPost(remote *PostResponse, local PostRequest, uploads []ImageUpload) []FieldChange {
+	var changes []FieldChange
+	add := func(field string, remoteValue, localValue interface{}) {
+		changes = append(changes, FieldChange{Field: field, Remote: remoteValue, Local: localValue})
+	}
+
+	if local.Title != "" && local.Title != remote.Title.Raw {
+		add("title", remote.Title.Raw, local.Title)
+	}
+	if local.Content != "" && normalizeUploadSizes(local.Content, uploads) != normalizeUploadSizes(remote.Content.Raw, uploads) {
+		add("content", remote.Content.Raw, local.Content)
+	}
+	if local.Status != "" && local.Status != remote.Status {
+		add("status", remote.Status, local.Status)
+	}
+	if local.Slug != "" && !strings.EqualFold(local.Slug, decodeSlug(remote.Slug)) {
+		add("slug", decodeSlug(remote.Slug), local.Slug)
+	}
+	if len(local.Categories) > 0 && !sameIDs(local.Categories, remote.Categories) {
+		add("categories", remote.Categories, local.Categories)
+	}
+	if len(local.Tags) > 0 && !sameIDs(local.Tags, remote.Tags) {
+		add("tags", remote.Tags, local.Tags)
+	}
+	if local.FeaturedMedia != 0 && local.FeaturedMedia != remote.FeaturedMedia {
+		add("featured_media", remote.FeaturedMedia, local.FeaturedMedia)
+	}
+	if local.Excerpt != "" && normalizeContent(local.Excerpt) != normalizeContent(remote.Excerpt.Raw) {
+		add("excerpt", remote.Excerpt.Raw, local.Excerpt)
+	}
+	if local.Author != 0 && local.Author != remote.Author {
+		add("author", remote.Author, local.Author)
+	}
+	if local.CommentStatus != "" && local.CommentStatus != remote.CommentStatus {
+		add("comment_status", remote.CommentStatus, local.CommentStatus)
+	}
+	if local.PingStatus != "" && local.PingStatus != remote.PingStatus {
+		add("ping_status", remote.PingStatus, local.PingStatus)
+	}
+	if local.Sticky != nil && *local.Sticky != remote.Sticky {
+		add("sticky", remote.Sticky, *local.Sticky)
+	}
+	if local.Format != "" && local.Format != remote.Format {
+		add("format", remote.Format, local.Format)
+	}
+	if local.Parent != 0 && local.Parent != remote.Parent {
+		add("parent", remote.Parent, local.Parent)
+	}
+	if local.MenuOrder != 0 && local.MenuOrder != remote.MenuOrder {
+		add("menu_order", remote.MenuOrder, local.MenuOrder)
+	}
+	if local.Template != "" && local.Template != remote.Template {
+		add("template", remote.Template, local.Template)
+	}
+
+	changes = append(changes, diffFields("meta", remote.Meta, local.Meta)...)
+	changes = append(changes, diffFields("acf", remote.ACF, local.ACF)...)
+	changes = append(changes, diffFields("aioseo_meta_data", remote.AIOSEO, local.AIOSEO)...)
+
+	return changes
+}
+
+// diffFields は meta などのキーと値の組をキーごとに比較します
+func diffFields(field string, remote FieldMap, local map[string]interface{}) []FieldChange {
+	var changes []FieldChange
+	for _, key := range sortedKeys(local) {
+		if !sameJSON(remote[key], local[key]) {
+			changes = append(changes, FieldChange{Field: field + "." + key, Remote: remote[key], Local: local[key]})
+		}
+	}
+	return changes
+}
+
+// ChangedFields は変更のあったフィールドだけを送信用のリクエストボディにします
+func ChangedFields(changes []FieldChange) map[string]interface{} {
+	fields := make(map[string]interface{})
+	for _, c := range changes {
+		parent, key, nested := strings.Cut(c.Field, ".")
+		if !nested {
+			fields[c.Field] = c.Local
+			continue
+		}
+		m, ok := fields[parent].(map[string]interface{})
+		if !ok {
+			m = make(map[string]interface{})
+			fields[parent] = m
+		}
+		m[key] = c.Local
+	}
+	return fields
+}
+
+// String は変更内容を1行で表示します。本文など長いフィールドは文字数のみ表示します。
+func (c FieldChange) String() string {
+	switch c.Field {
+	case "content", "excerpt":
+		r, _ := c.Remote.(string)
+		l, _ := c.Local.(string)
+		return fmt.Sprintf("%s: %d文字 → %d文字", c.Field, utf8.RuneCountInString(r), utf8.RuneCountInString(l))
+	}
+	return fmt.Sprintf("%s: %s → %s", c.Field, formatValue(c.Remote), formatValue(c.Local))
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "（なし）"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// normalizeContent は比較のために行末の空白と前後の空行を取り除きます
+func normalizeContent(s string) string {
+	lines := strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// reImageSize は WordPress が縮小した画像の URL に付けるサイズ（-300x200）です
+var reImageSize = regexp.MustCompile(`(<img[^>]*?src=")([^"]*?)-\d+x\d+(\.[A-Za-z0-9]+)"`)
+
+// normalizeUploadSizes は比較のために本文を正規化し、アップロードした画像の縮小版の URL を元の画像の URL に戻します。
+// アップロードした画像以外の URL とファイル名の連番はそのまま比較します。
+func normalizeUploadSizes(content string, uploads []ImageUpload) string {
+	content = normalizeContent(content)
+	if len(uploads) == 0 {
+		return content
+	}
+	uploaded := make(map[string]bool, len(uploads))
+	for _, upload := range uploads {
+		uploaded[upload.Media.URL] = true
+	}
+	return reImageSize.ReplaceAllStringFunc(content, func(match string) string {
+		m := reImageSize.FindStringSubmatch(match)
+		if original := m[2] + m[3]; uploaded[original] {
+			return m[1] + original + `"`
+		}
+		return match
+	})
+}
+
+// sameIDs は順序を無視してIDの集合が等しいかを返します
+func sameIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]int(nil), a...)
+	y := append([]int(nil), b...)
+	sort.Ints(x)
+	sort.Ints(y)
+	return reflect.DeepEqual(x, y)
+}
+
+// sameJSON は JSON として同じ値かを返します（数値の型の違いなどを吸収する）
+func sameJSON(a, b interface{}) bool {
+	x, errA := json.Marshal(a)
+	y, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	var ax, by interface{}
+	json.Unmarshal(x, &ax)
+	json.Unmarshal(y, &by)
+	return reflect.DeepEqual(ax, by)
+}
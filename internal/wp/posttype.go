@@ -0,0 +1,156 @@
+package wp
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strconv"
+)
+
+// 組み込みの投稿タイプは問い合わせずに解決する
+var (
+	postTypePost = PostType{Slug: "post", Name: "投稿", RestBase: "posts", Taxonomies: []string{"category", "post_tag"}}
+	postTypePage = PostType{Slug: "page", Name: "固定ページ", RestBase: "pages", Hierarchical: true}
+)
+
+// GetPostTypes はサイトに登録されている投稿タイプをスラッグをキーにして取得します
+func GetPostTypes(client API) (map[string]PostType, error) {
+	types, err := client.PostTypes()
+	if err != nil {
+		return nil, fmt.Errorf("投稿タイプ取得エラー: %w", err)
+	}
+	return types, nil
+}
+
+// PostTypes はサイトに登録されている投稿タイプを取得し、キャッシュします
+func (c *Client) PostTypes() (map[string]PostType, error) {
+	c.Cache.mu.Lock()
+	defer c.Cache.mu.Unlock()
+
+	if c.Cache.postTypes != nil {
+		return c.Cache.postTypes, nil
+	}
+
+	var types map[string]PostType
+	if err := getJSON(c, "/wp-json/wp/v2/types?context=edit", &types); err != nil {
+		return nil, err
+	}
+	for slug, t := range types {
+		if t.RestBase == "" {
+			t.RestBase = slug
+			types[slug] = t
+		}
+	}
+	c.Cache.postTypes = types
+	return types, nil
+}
+
+// ResolvePostType はメタデータの Type から投稿タイプを解決します。
+// 空文字・post・page はサイトに問い合わせず、それ以外はスラッグまたは REST base で検索します。
+func ResolvePostType(client API, name string) (*PostType, error) {
+	switch name {
+	case "", "post", "posts":
+		t := postTypePost
+		return &t, nil
+	case "page", "pages":
+		t := postTypePage
+		return &t, nil
+	}
+
+	types, err := GetPostTypes(client)
+	if err != nil {
+		return nil, err
+	}
+	if t, ok := types[name]; ok {
+		return &t, nil
+	}
+	for _, t := range types {
+		if t.RestBase == name {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("投稿タイプが見つかりません: %s", name)
+}
+
+// HasTaxonomy は投稿タイプがタクソノミー（category、post_tag など）に対応しているかを返します
+func (t PostType) HasTaxonomy(taxonomy string) bool {
+	for _, tax := range t.Taxonomies {
+		if tax == taxonomy {
+			return true
+		}
+	}
+	return false
+}
+
+// FindPostID はスラッグから投稿IDを検索します。数値が指定された場合はそのままIDとして扱います。
+func FindPostID(client API, postType *PostType, slug string) (int, error) {
+	if id, err := strconv.Atoi(slug); err == nil {
+		return id, nil
+	}
+
+	posts, err := client.ListPosts(postType.RestBase, PostQuery{
+		Slug:   slug,
+		Status: []string{"publish", "future", "draft", "pending", "private"},
+		Fields: []string{"id", "slug"},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(posts) == 0 {
+		return 0, fmt.Errorf("%sが見つかりません: %s", postType.Name, slug)
+	}
+	return posts[0].ID, nil
+}
+
+// postSlug は投稿IDからスラッグを取得します
+func postSlug(client API, postType *PostType, id int) (string, error) {
+	post, err := client.GetPostOfType(postType.RestBase, id)
+	if err != nil {
+		return "", err
+	}
+	return decodeSlug(post.Slug), nil
+}
+
+// decodeSlug は WordPress がパーセントエンコードして保存した日本語スラッグを元に戻します
+func decodeSlug(slug string) string {
+	if decoded, err := url.PathUnescape(slug); err == nil {
+		return decoded
+	}
+	return slug
+}
+
+// categoryNames はカテゴリーIDをカテゴリー名に変換します
+func categoryNames(client API, ids []int) ([]string, error) {
+	categories, err := client.Categories()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, cat := range categories {
+			if cat.ID == id {
+				names = append(names, html.UnescapeString(cat.Name))
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// tagNames はタグIDをタグ名に変換します
+func tagNames(client API, ids []int) ([]string, error) {
+	tags, err := client.Tags()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		for _, tag := range tags {
+			if tag.ID == id {
+				names = append(names, html.UnescapeString(tag.Name))
+				break
+			}
+		}
+	}
+	return names, nil
+}
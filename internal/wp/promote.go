@@ -0,0 +1,220 @@
+package wp
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var reUploadURL = regexp.MustCompile(`https?://[^\s"'()<>]+/wp-content/uploads/[^\s"'()<>]+`)
+
+// reHref はリンク先の URL です。# 以降の見出しへのリンクは含みません。
+var reHref = regexp.MustCompile(`(href=")([^"#]*)`)
+
+// PromotePost は src の投稿を dst にコピーします。dstID が 0 の場合は dst に新しく作成します。
+// 本文中の画像とアイキャッチ画像は dst にアップロードし直し、カテゴリー・タグ・親ページは名前とスラッグで対応付けます。
+// 本文中のほかの記事へのリンクは links（PromoteLinks の結果）で src の URL から dst の URL に置き換えます。
+// 投稿者は dst のユーザーと一致するとは限らないため、コピーしません。
+func PromotePost(src, dst API, typeName string, srcID, dstID int, links map[string]string) (*PostResponse, error) {
+	srcType, err := ResolvePostType(src, typeName)
+	if err != nil {
+		return nil, err
+	}
+	dstType, err := ResolvePostType(dst, typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := src.GetPostOfType(srcType.RestBase, srcID)
+	if err != nil {
+		return nil, fmt.Errorf("投稿取得エラー: %w", err)
+	}
+	if post.Status == "trash" {
+		return nil, fmt.Errorf("投稿ID %d はゴミ箱にあります", srcID)
+	}
+
+	// src のサイトのホストは投稿の URL から分かる
+	media := &mediaCopier{src: src, dst: dst, srcURL: post.Link, urls: make(map[string]string)}
+	content, err := media.copyContent(post.Content.Raw)
+	if err != nil {
+		return nil, err
+	}
+	content = replaceLinks(content, links)
+
+	req := PostRequest{
+		Title:         post.Title.Raw,
+		Content:       content,
+		Status:        post.Status,
+		Slug:          decodeSlug(post.Slug),
+		Excerpt:       post.Excerpt.Raw,
+		CommentStatus: post.CommentStatus,
+		PingStatus:    post.PingStatus,
+		MenuOrder:     post.MenuOrder,
+		Template:      post.Template,
+	}
+	if dstType.Slug == "post" {
+		sticky := post.Sticky
+		req.Sticky = &sticky
+		req.Format = post.Format
+	}
+
+	if post.FeaturedMedia != 0 {
+		if req.FeaturedMedia, err = media.copyMedia(post.FeaturedMedia); err != nil {
+			return nil, fmt.Errorf("アイキャッチ画像のコピーエラー: %w", err)
+		}
+	}
+
+	if srcType.HasTaxonomy("category") && dstType.HasTaxonomy("category") {
+		names, err := categoryNames(src, post.Categories)
+		if err != nil {
+			return nil, fmt.Errorf("カテゴリー取得エラー: %w", err)
+		}
+		if req.Categories, err = GetCategoryIDs(dst, names); err != nil {
+			return nil, fmt.Errorf("カテゴリー処理エラー: %w", err)
+		}
+	}
+	if srcType.HasTaxonomy("post_tag") && dstType.HasTaxonomy("post_tag") {
+		names, err := tagNames(src, post.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("タグ取得エラー: %w", err)
+		}
+		if req.Tags, err = GetTagIDs(dst, names); err != nil {
+			return nil, fmt.Errorf("タグ処理エラー: %w", err)
+		}
+	}
+
+	if dstType.Hierarchical && post.Parent != 0 {
+		slug, err := postSlug(src, srcType, post.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("親ページ取得エラー: %w", err)
+		}
+		if req.Parent, err = FindPostID(dst, dstType, slug); err != nil {
+			return nil, fmt.Errorf("親ページ %s が見つかりません。先に親ページをコピーしてください: %w", slug, err)
+		}
+	}
+
+	// カスタムフィールドは dst に登録されているものだけをコピーする
+	schema, err := GetPostSchema(dst, dstType.RestBase)
+	if err != nil {
+		return nil, err
+	}
+	registered := schema.MetaFields()
+	for key, value := range post.Meta {
+		if _, ok := registered[key]; !ok {
+			continue
+		}
+		if req.Meta == nil {
+			req.Meta = make(map[string]interface{})
+		}
+		req.Meta[key] = value
+	}
+	if len(post.ACF) > 0 && schema.HasACF() {
+		req.ACF = post.ACF
+	}
+	if _, ok := schema.Properties["aioseo_meta_data"]; ok && len(post.AIOSEO) > 0 {
+		req.AIOSEO = post.AIOSEO
+	}
+
+	if dstID == 0 {
+		return dst.CreatePostOfType(dstType.RestBase, req)
+	}
+	return dst.UpdatePostOfType(dstType.RestBase, dstID, req)
+}
+
+// replaceLinks は本文のリンク先の URL を links で置き換えます
+func replaceLinks(content string, links map[string]string) string {
+	if len(links) == 0 {
+		return content
+	}
+	return reHref.ReplaceAllStringFunc(content, func(match string) string {
+		m := reHref.FindStringSubmatch(match)
+		if u, ok := links[m[2]]; ok {
+			return m[1] + u
+		}
+		return match
+	})
+}
+
+// mediaCopier は src のメディアを dst にアップロードし直し、URL の対応を記録します
+type mediaCopier struct {
+	src, dst API
+	// srcURL は src のサイトの URL です。ホストが同じ画像だけをコピーします。
+	srcURL string
+	urls   map[string]string
+}
+
+// copyContent は本文中の src のアップロード画像を dst にコピーし、URL を置き換えます
+func (m *mediaCopier) copyContent(content string) (string, error) {
+	var copyErr error
+	result := reUploadURL.ReplaceAllStringFunc(content, func(u string) string {
+		if copyErr != nil || !sameHost(u, m.srcURL) {
+			return u
+		}
+		newURL, err := m.copyURL(u)
+		if err != nil {
+			copyErr = fmt.Errorf("画像のコピーエラー（%s）: %w", u, err)
+			return u
+		}
+		return newURL
+	})
+	return result, copyErr
+}
+
+// copyMedia はメディアIDの画像を dst にコピーし、dst のメディアIDを返します
+func (m *mediaCopier) copyMedia(id int) (int, error) {
+	media, err := GetMedia(m.src, id)
+	if err != nil {
+		return 0, err
+	}
+	uploaded, err := m.upload(media.URL)
+	if err != nil {
+		return 0, err
+	}
+	return uploaded.ID, nil
+}
+
+func (m *mediaCopier) copyURL(u string) (string, error) {
+	if newURL, ok := m.urls[u]; ok {
+		return newURL, nil
+	}
+	uploaded, err := m.upload(u)
+	if err != nil {
+		return "", err
+	}
+	return uploaded.URL, nil
+}
+
+// upload は src から画像をダウンロードして dst にアップロードします。
+// 同じ内容の画像はキャッシュにより一度だけアップロードされます。
+func (m *mediaCopier) upload(u string) (*MediaResponse, error) {
+	data, err := m.src.DownloadMedia(MediaResponse{URL: u})
+	if err != nil {
+		return nil, fmt.Errorf("画像ダウンロードエラー: %w", err)
+	}
+
+	name := u
+	if parsed, err := url.Parse(u); err == nil {
+		name = parsed.Path
+	}
+	uploaded, err := UploadImageData(m.dst, path.Base(name), data)
+	if err != nil {
+		return nil, err
+	}
+	m.urls[u] = uploaded.URL
+	return uploaded, nil
+}
+
+// sameHost は URL が baseURL と同じホストかを返します
+func sameHost(u, baseURL string) bool {
+	a, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	b, err := url.Parse(baseURL)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(a.Host, b.Host)
+}
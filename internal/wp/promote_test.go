@@ -0,0 +1,80 @@
+package wp_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+const promotedArticle = `{
+  "Title": "コピーする記事",
+  "Permalink": "promoted",
+  "Status": "publish",
+  "Category": [],
+  "Tag": ["Go"]
+}
+---
+[前の記事](a.md#はじめに) も参照してください。
+
+![図](images/y.png)
+`
+
+// ステージングの投稿を本番にコピーすると、記事へのリンクと画像は本番のサイトのものになる
+func TestPromotePost(t *testing.T) {
+	staging := wptest.NewFake()
+	staging.BaseURL = "http://staging.test"
+	production := wptest.NewFake()
+	ws := wptest.NewWorkspace(
+		map[string]string{"a.md": plainArticle, "b.md": promotedArticle},
+		map[string]string{"y.png": "\x89PNG image data"},
+	)
+	stagingWS := *ws
+	stagingWS.Profile = "staging"
+	ctx := context.Background()
+
+	for _, name := range []string{"a", "b"} {
+		if _, err := (&wp.Publisher{Client: staging, Workspace: &stagingWS}).Create(ctx, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	metadata, body, err := stagingWS.ReadArticle("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// リンク先の記事がコピー先にまだ投稿されていない場合はコピーしない
+	if _, err := wp.PromoteLinks(staging, &stagingWS, production, ws, "b", body); !errors.Is(err, wp.ErrNotPublished) {
+		t.Fatalf("PromoteLinks before publishing the linked article: err = %v, want ErrNotPublished", err)
+	}
+
+	target, err := (&wp.Publisher{Client: production, Workspace: ws}).Create(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	links, err := wp.PromoteLinks(staging, &stagingWS, production, ws, "b", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := wp.PromotePost(staging, production, "", metadata.PostID, 0, links)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	post, _ := production.Post(resp.ID)
+	if strings.Contains(post.Content.Raw, "staging.test") {
+		t.Errorf("content links to the staging site: %q", post.Content.Raw)
+	}
+	if !strings.Contains(post.Content.Raw, `href="`+target.Link+`#はじめに"`) {
+		t.Errorf("content = %q, want a link to %s", post.Content.Raw, target.Link)
+	}
+	if media := production.Media(); len(media) != 1 || !strings.Contains(post.Content.Raw, media[0].URL) {
+		t.Errorf("media = %v, content = %q", media, post.Content.Raw)
+	}
+	if tags, _ := production.Tags(); len(tags) != 1 || !strings.EqualFold(tags[0].Name, "Go") || len(post.Tags) != 1 {
+		t.Errorf("tags = %v, post tags = %v", tags, post.Tags)
+	}
+}
@@ -0,0 +1,375 @@
+package wp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// 競合時の解決方法
+const (
+	// ResolveOurs は WordPress 上で変更されていてもローカルの内容で上書きします
+	ResolveOurs = "ours"
+	// ResolveTheirs は WordPress 上の内容をローカルの記事に取り込みます
+	ResolveTheirs = "theirs"
+)
+
+// 公開処理の段階
+const (
+	StageRead     = "read"
+	StageConflict = "conflict"
+	StageLinks    = "links"
+	StageImages   = "images"
+	StageTerms    = "terms"
+	StageSend     = "send"
+	StageDone     = "done"
+)
+
+// Publisher は記事を読み込み、画像とタームを準備して WordPress に投稿・更新します
+type Publisher struct {
+	// Client は投稿先の WordPress です。通常は *Client、テストでは wptest.Fake を使います。
+	Client API
+	// Workspace は記事と画像の置き場所です。nil の場合は DefaultWorkspace を使います。
+	Workspace *Workspace
+	// Force が true の場合、update で変更のないフィールドも含めてすべて送信します
+	Force bool
+	// Resolution は WordPress 上で変更されていた場合の解決方法です（空、ResolveOurs、ResolveTheirs）。
+	// 空の場合は ConflictError を返します。
+	Resolution string
+	// OnProgress は各段階の開始時に呼ばれます
+	OnProgress func(PublishEvent)
+	// Logger は各段階と警告を出力します。nil の場合は Client が *Client であればその Logger を使います。
+	Logger *slog.Logger
+}
+
+// PublishEvent は公開処理の進み具合です
+type PublishEvent struct {
+	Article string
+	Stage   string
+	// Detail はアップロード中の画像など、段階の補足です
+	Detail string
+}
+
+// PublishResult は記事1つの公開結果です
+type PublishResult struct {
+	Article string `json:"article"`
+	PostID  int    `json:"post_id"`
+	Link    string `json:"link"`
+	Status  string `json:"status"`
+	// Created は新しく投稿を作成した場合に true です
+	Created bool `json:"created"`
+	// Pulled は競合のため WordPress 上の内容をローカルに取り込んだ場合に true です
+	Pulled            bool          `json:"pulled,omitempty"`
+	CreatedCategories []Category    `json:"created_categories,omitempty"`
+	CreatedTags       []Tag         `json:"created_tags,omitempty"`
+	UploadedMedia     []ImageUpload `json:"uploaded_media,omitempty"`
+	Warnings          []string      `json:"warnings,omitempty"`
+	// Changes は update で送信したフィールドの変更です。Force の場合は空です。
+	Changes []FieldChange `json:"changes,omitempty"`
+}
+
+// ConflictError は最後の投稿以降に WordPress 上で投稿が変更されていたことを表します
+type ConflictError struct {
+	*Conflict
+	// LocalContent はローカルの本文を HTML に変換したものです
+	LocalContent string
+}
+
+// Diff は共通の祖先、WordPress 上、ローカルの本文の差分を返します
+func (e *ConflictError) Diff() string {
+	return e.ThreeWayDiff(e.LocalContent)
+}
+
+func (p *Publisher) workspace() *Workspace {
+	if p.Workspace != nil {
+		return p.Workspace
+	}
+	return DefaultWorkspace
+}
+
+func (p *Publisher) log() *slog.Logger {
+	if p.Logger != nil {
+		return p.Logger
+	}
+	if c, ok := p.Client.(*Client); ok {
+		return c.log()
+	}
+	return discardLogger
+}
+
+func (p *Publisher) progress(ctx context.Context, name, stage, detail string) {
+	p.log().InfoContext(ctx, "公開処理", "article", name, "stage", stage, "detail", detail)
+	if p.OnProgress != nil {
+		p.OnProgress(PublishEvent{Article: name, Stage: stage, Detail: detail})
+	}
+}
+
+// warn は警告を結果に追加し、ロガーにも出力します
+func (p *Publisher) warn(ctx context.Context, result *PublishResult, warning string) {
+	p.log().WarnContext(ctx, warning, "article", result.Article)
+	result.Warnings = append(result.Warnings, warning)
+}
+
+// Publish は記事に post_id があれば更新し、なければ新しく投稿します
+func (p *Publisher) Publish(ctx context.Context, name string) (*PublishResult, error) {
+	metadata, _, err := p.workspace().ReadArticle(name)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+	return p.publish(ctx, name, metadata.PostID == 0)
+}
+
+// Create は記事を新しく投稿し、投稿IDを記事に記録します
+func (p *Publisher) Create(ctx context.Context, name string) (*PublishResult, error) {
+	return p.publish(ctx, name, true)
+}
+
+// Update は記事の post_id の投稿を更新します
+func (p *Publisher) Update(ctx context.Context, name string) (*PublishResult, error) {
+	return p.publish(ctx, name, false)
+}
+
+func (p *Publisher) publish(ctx context.Context, name string, create bool) (*PublishResult, error) {
+	client := withContext(p.Client, ctx)
+	ws := p.workspace()
+	result := &PublishResult{Article: name, Created: create}
+
+	// 指定されたファイル名の記事を読み込む
+	p.progress(ctx, name, StageRead, "")
+	metadata, body, err := ws.ReadArticle(name)
+	if err != nil {
+		return nil, fmt.Errorf("記事読み取りエラー: %w", err)
+	}
+
+	if !create && metadata.PostID == 0 {
+		return nil, fmt.Errorf("エラー: %w", ErrNotPublished)
+	}
+
+	if err := metadata.Validate(); err != nil {
+		return nil, fmt.Errorf("メタデータの検証エラー:\n%w", err)
+	}
+
+	postType, err := ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return nil, fmt.Errorf("投稿タイプ取得エラー: %w", err)
+	}
+
+	// 最後の投稿以降に WordPress 上で変更されていないかを、画像のアップロードなどの前に確認する
+	var current *PostResponse
+	if !create {
+		p.progress(ctx, name, StageConflict, "")
+		current, err = client.GetPostOfType(postType.RestBase, metadata.PostID)
+		if err != nil {
+			return nil, fmt.Errorf("投稿取得エラー: %w", err)
+		}
+		conflict, err := CheckConflict(client, postType.RestBase, metadata.Sync, current)
+		if err != nil {
+			return nil, fmt.Errorf("競合確認エラー: %w", err)
+		}
+		if conflict != nil {
+			switch p.Resolution {
+			case ResolveOurs:
+				p.warn(ctx, result, fmt.Sprintf("%v。ローカルの内容で上書きします", conflict))
+			case ResolveTheirs:
+				p.warn(ctx, result, fmt.Sprintf("%v。WordPress 上の内容を取り込みます", conflict))
+				return p.takeTheirs(ctx, client, name, metadata, result)
+			default:
+				return nil, &ConflictError{Conflict: conflict, LocalContent: ConvertMarkdownToHTML(body)}
+			}
+		}
+	}
+
+	var authorID int
+	if metadata.Author != "" {
+		authorID, err = FindUserID(client, metadata.Author)
+		if err != nil {
+			return nil, fmt.Errorf("投稿者取得エラー: %w", err)
+		}
+	}
+
+	var parentID int
+	if metadata.Parent != "" {
+		if !postType.Hierarchical {
+			return nil, fmt.Errorf("エラー: 投稿タイプ %s は親ページを指定できません", postType.Slug)
+		}
+		parentID, err = FindPostID(client, postType, metadata.Parent)
+		if err != nil {
+			return nil, fmt.Errorf("親ページ取得エラー: %w", err)
+		}
+	}
+
+	var schema *PostSchema
+	if len(metadata.Meta) > 0 || len(metadata.ACF) > 0 || metadata.SEO != nil {
+		schema, err = GetPostSchema(client, postType.RestBase)
+		if err != nil {
+			return nil, err
+		}
+		if err := ValidateFields(schema, metadata); err != nil {
+			return nil, fmt.Errorf("カスタムフィールドの検証エラー:\n%w", err)
+		}
+	}
+
+	var seoPlugin SEOPlugin
+	if metadata.SEO != nil {
+		seoPlugin, err = DetectSEOPlugin(schema)
+		if err != nil {
+			return nil, fmt.Errorf("SEO設定エラー: %w", err)
+		}
+		warnings, err := ValidateSEO(metadata.SEO)
+		if err != nil {
+			return nil, fmt.Errorf("SEO設定エラー: %w", err)
+		}
+		for _, w := range warnings {
+			p.warn(ctx, result, w)
+		}
+	}
+
+	// ほかの記事へのリンクを、リンク先の投稿の URL に置き換える。
+	// 置き換えた本文は送信にだけ使い、同期状態のハッシュには記事ファイルの本文を使う
+	p.progress(ctx, name, StageLinks, "")
+	linked, err := ResolveArticleLinks(client, ws, name, body)
+	if err != nil {
+		return nil, fmt.Errorf("記事へのリンクのエラー: %w", err)
+	}
+
+	p.progress(ctx, name, StageImages, "")
+	content, uploads, uploadErrs := UploadContentImages(client, ws, linked)
+	result.UploadedMedia = append(result.UploadedMedia, uploads...)
+	for _, upload := range uploads {
+		p.log().InfoContext(ctx, "画像", "article", name, "path", upload.Path, "media_id", upload.Media.ID, "uploaded", upload.Uploaded)
+	}
+	for _, err := range uploadErrs {
+		p.warn(ctx, result, fmt.Sprintf("画像をアップロードできなかったため参照をそのまま残します: %v", err))
+	}
+
+	p.progress(ctx, name, StageTerms, "")
+	var categoryIDs []int
+	if postType.HasTaxonomy("category") {
+		categoryIDs, result.CreatedCategories, err = ResolveCategories(client, metadata.Category)
+		if err != nil {
+			return nil, fmt.Errorf("カテゴリーID取得エラー: %w", err)
+		}
+	}
+
+	var mediaID int
+	if metadata.Image != "" {
+		p.progress(ctx, name, StageImages, metadata.Image)
+		upload, err := uploadImageFile(client, ws.Images, metadata.Image)
+		if err != nil {
+			return nil, fmt.Errorf("画像アップロードエラー: %w", err)
+		}
+		result.UploadedMedia = append(result.UploadedMedia, *upload)
+		mediaID = upload.Media.ID
+	}
+
+	var tagIDs []int
+	if postType.HasTaxonomy("post_tag") {
+		tagIDs, result.CreatedTags, err = ResolveTags(client, metadata.Tag)
+		if err != nil {
+			return nil, fmt.Errorf("タグID取得エラー: %w", err)
+		}
+	}
+
+	post := PostRequest{
+		Title:         metadata.Title,
+		Content:       ConvertMarkdownToHTML(content),
+		Status:        metadata.PostStatus(ws.DefaultStatus),
+		Slug:          metadata.Permalink,
+		Categories:    categoryIDs,
+		Tags:          tagIDs,
+		FeaturedMedia: mediaID,
+		Excerpt:       metadata.Excerpt,
+		Author:        authorID,
+		CommentStatus: metadata.CommentStatus,
+		PingStatus:    metadata.PingStatus,
+		Sticky:        metadata.Sticky,
+		Format:        metadata.Format,
+		Parent:        parentID,
+		MenuOrder:     metadata.MenuOrder,
+		Template:      metadata.Template,
+		Meta:          metadata.Meta,
+		ACF:           metadata.ACF,
+	}
+
+	if metadata.SEO != nil {
+		var ogImage *MediaResponse
+		if img := metadata.SEO.OGImage; img != "" && !strings.HasPrefix(img, "http://") && !strings.HasPrefix(img, "https://") {
+			p.progress(ctx, name, StageImages, img)
+			upload, err := uploadImageFile(client, ws.Images, img)
+			if err != nil {
+				return nil, fmt.Errorf("OGP画像アップロードエラー: %w", err)
+			}
+			result.UploadedMedia = append(result.UploadedMedia, *upload)
+			ogImage = &upload.Media
+		}
+		if err := ApplySEO(&post, seoPlugin, schema, metadata.SEO, ogImage); err != nil {
+			return nil, fmt.Errorf("SEO設定エラー: %w", err)
+		}
+	}
+
+	p.progress(ctx, name, StageSend, "")
+	var resp *PostResponse
+	switch {
+	case create:
+		resp, err = client.CreatePostOfType(postType.RestBase, post)
+		if err != nil {
+			return nil, fmt.Errorf("投稿エラー: %w", err)
+		}
+		// メタデータにpost_idを追加（同期状態と合わせて後で保存）
+		metadata.PostID = resp.ID
+	case p.Force:
+		resp, err = client.UpdatePostOfType(postType.RestBase, metadata.PostID, post)
+		if err != nil {
+			return nil, fmt.Errorf("更新エラー: %w", err)
+		}
+	default:
+		// 現在の投稿と比較し、変更のあるフィールドだけを送信する
+		result.Changes = DiffPost(current, post, uploads)
+		if len(result.Changes) == 0 {
+			resp = current
+			break
+		}
+		resp, err = client.UpdatePostFields(postType.RestBase, metadata.PostID, ChangedFields(result.Changes))
+		if err != nil {
+			return nil, fmt.Errorf("更新エラー: %w", err)
+		}
+	}
+
+	if err := ws.RecordPost(name, resp.ID, postType.Slug); err != nil {
+		return nil, err
+	}
+
+	// 次回の更新で競合とローカルの変更を検出できるよう、投稿後の状態を記録する
+	state := NewSyncState(resp)
+	state.LocalHash = HashArticle(metadata, body)
+	if metadata.Sync == nil || *metadata.Sync != *state {
+		metadata.Sync = state
+		if err := UpdateMetadataFS(ws.Articles, name, metadata); err != nil {
+			return nil, fmt.Errorf("メタデータ更新エラー: %w", err)
+		}
+	}
+
+	result.PostID = resp.ID
+	result.Link = resp.Link
+	result.Status = resp.Status
+	p.progress(ctx, name, StageDone, resp.Link)
+	return result, nil
+}
+
+// takeTheirs は WordPress 上の内容をローカルの記事ファイルに取り込み、同期状態を更新します
+func (p *Publisher) takeTheirs(ctx context.Context, client API, name string, metadata ArticleMetadata, result *PublishResult) (*PublishResult, error) {
+	metadata, body, err := PullPost(client, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("取得エラー: %w", err)
+	}
+
+	if err := WriteArticleFS(p.workspace().Articles, name, metadata, body); err != nil {
+		return nil, fmt.Errorf("記事書き込みエラー: %w", err)
+	}
+
+	result.PostID = metadata.PostID
+	result.Pulled = true
+	p.progress(ctx, name, StageDone, "")
+	return result, nil
+}
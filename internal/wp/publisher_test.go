@@ -0,0 +1,182 @@
+package wp_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+const imageArticle = `{
+  "Title": "画像のある記事",
+  "Permalink": "image-article",
+  "Status": "publish",
+  "Category": [],
+  "Tag": []
+}
+---
+## はじめに
+
+![図](images/y.png)
+`
+
+// newSite は fake を REST API として公開し、記事 files と画像 y.png の置き場所を作成します
+func newSite(t *testing.T, fake *wptest.Fake, files map[string]string) *wp.Workspace {
+	t.Helper()
+	server := wptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return &wp.Workspace{
+		Articles:    wptest.NewMemFS(files),
+		Images:      wptest.NewMemFS(map[string]string{"y.png": "\x89PNG image data"}),
+		ImagePrefix: "images",
+	}
+}
+
+// newPublisher は別のプロセスと同じく、キャッシュを共有しないクライアントで Publisher を作成します
+func newPublisher(fake *wptest.Fake, ws *wp.Workspace) *wp.Publisher {
+	return &wp.Publisher{Client: wp.NewClient(fake.BaseURL, "admin", "password"), Workspace: ws}
+}
+
+func TestUpdateUnchangedArticleInNewProcess(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := newSite(t, fake, map[string]string{"a.md": imageArticle})
+	ctx := context.Background()
+
+	if _, err := newPublisher(fake, ws).Create(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	result, err := newPublisher(fake, ws).Update(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 0 {
+		t.Errorf("Changes = %v, want none", result.Changes)
+	}
+	for _, upload := range result.UploadedMedia {
+		if upload.Uploaded {
+			t.Errorf("%s uploaded again as %s", upload.Path, upload.Media.URL)
+		}
+	}
+	if media := fake.Media(); len(media) != 1 {
+		t.Errorf("media library has %d items, want 1: %v", len(media), media)
+	}
+}
+
+func TestUploadReusesSameNameOnlyForSameContent(t *testing.T) {
+	fake := wptest.NewFake()
+	ws := newSite(t, fake, map[string]string{"a.md": imageArticle})
+	client := wp.NewClient(fake.BaseURL, "admin", "password")
+
+	// 同じ名前で内容の異なる画像はアップロードする
+	if _, err := wp.UploadImageData(client, "y.png", []byte("another image")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newPublisher(fake, ws).Create(context.Background(), "a"); err != nil {
+		t.Fatal(err)
+	}
+	if media := fake.Media(); len(media) != 2 {
+		t.Errorf("media library has %d items, want 2: %v", len(media), media)
+	}
+}
+
+// プロファイルと既定の公開状態の異なる Publisher を1つのプロセスで使える
+func TestPublishersWithDifferentProfiles(t *testing.T) {
+	const article = `{
+  "Title": "記事",
+  "Category": [],
+  "Tag": []
+}
+---
+本文
+`
+	articles := wptest.NewMemFS(map[string]string{"a.md": article})
+	production, staging := wptest.NewFake(), wptest.NewFake()
+	for _, fake := range []*wptest.Fake{production, staging} {
+		server := wptest.NewServer(fake)
+		t.Cleanup(server.Close)
+	}
+	ctx := context.Background()
+
+	stagingPublisher := newPublisher(staging, &wp.Workspace{Articles: articles, Profile: "staging", DefaultStatus: "draft"})
+	stagingResult, err := stagingPublisher.Create(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	productionResult, err := newPublisher(production, &wp.Workspace{Articles: articles}).Create(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stagingResult.Status != "draft" || productionResult.Status != "publish" {
+		t.Errorf("status = %s (staging), %s (production); want draft, publish", stagingResult.Status, productionResult.Status)
+	}
+
+	metadata, _, err := wp.ReadArticleFS(articles, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.PostID != productionResult.PostID || metadata.StateFor("staging").PostID != stagingResult.PostID {
+		t.Errorf("post_id = %d, staging = %d; want %d, %d",
+			metadata.PostID, metadata.StateFor("staging").PostID, productionResult.PostID, stagingResult.PostID)
+	}
+}
+
+// 本文の画像を別の画像に差し替えた場合は、ファイル名の連番だけが異なっても本文の変更として送信する
+func TestUpdateSwappedInlineImage(t *testing.T) {
+	fake := wptest.NewFake()
+	article := strings.Replace(imageArticle, "images/y.png", "images/step-1.png", 1)
+	ws := wptest.NewWorkspace(map[string]string{"a.md": article}, map[string]string{
+		"step-1.png": "\x89PNG step 1",
+		"step-2.png": "\x89PNG step 2",
+	})
+	publisher := &wp.Publisher{Client: fake, Workspace: ws}
+	ctx := context.Background()
+
+	if _, err := publisher.Create(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	metadata, body, err := wp.ReadArticleFS(ws.Articles, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body = strings.Replace(body, "images/step-1.png", "images/step-2.png", 1)
+	if err := wp.WriteArticleFS(ws.Articles, "a", metadata, body); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := publisher.Update(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Changes) != 1 || result.Changes[0].Field != "content" {
+		t.Fatalf("Changes = %v, want content", result.Changes)
+	}
+	post, _ := fake.Post(result.PostID)
+	if !strings.Contains(post.Content.Raw, "step-2.png") || strings.Contains(post.Content.Raw, "step-1.png") {
+		t.Errorf("content = %q", post.Content.Raw)
+	}
+}
+
+func TestDiffPostImageSources(t *testing.T) {
+	uploads := []wp.ImageUpload{{Path: "y.png", Media: wp.MediaResponse{URL: "https://example.com/uploads/y.png"}}}
+	local := wp.PostRequest{Content: `<p><img src="https://example.com/uploads/y.png" alt="図"></p>`}
+
+	tests := []struct {
+		name   string
+		remote string
+		want   bool
+	}{
+		{"同じ画像", `<p><img src="https://example.com/uploads/y.png" alt="図"></p>`, false},
+		{"アップロードした画像の縮小版", `<p><img src="https://example.com/uploads/y-300x200.png" alt="図"></p>`, false},
+		{"連番の付いた別の画像", `<p><img src="https://example.com/uploads/y-1.png" alt="図"></p>`, true},
+		{"別のディレクトリの同じ名前の画像", `<p><img src="https://example.com/2020/y.png" alt="図"></p>`, true},
+		{"アップロードしていない画像の縮小版", `<p><img src="https://example.com/uploads/z-300x200.png" alt="図"></p>`, true},
+	}
+	for _, tt := range tests {
+		remote := &wp.PostResponse{Content: wp.RenderedField{Raw: tt.remote}}
+		if got := len(wp.DiffPost(remote, local, uploads)) > 0; got != tt.want {
+			t.Errorf("%s: changed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
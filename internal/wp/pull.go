@@ -0,0 +1,85 @@
+package wp
+
+import "fmt"
+
+// PullPost は WordPress 上の投稿を取得し、ローカルのメタデータと本文マークダウンに反映した結果を返します。
+// Image はローカルの画像ファイル名のため変更しません。
+func PullPost(client API, metadata ArticleMetadata) (ArticleMetadata, string, error) {
+	if metadata.PostID == 0 {
+		return metadata, "", ErrNotPublished
+	}
+
+	postType, err := ResolvePostType(client, metadata.Type)
+	if err != nil {
+		return metadata, "", err
+	}
+
+	post, err := client.GetPostOfType(postType.RestBase, metadata.PostID)
+	if err != nil {
+		return metadata, "", fmt.Errorf("投稿取得エラー: %w", err)
+	}
+
+	metadata.Title = post.Title.Raw
+	metadata.Permalink = decodeSlug(post.Slug)
+	metadata.Excerpt = post.Excerpt.Raw
+	metadata.MenuOrder = post.MenuOrder
+	metadata.Template = post.Template
+
+	if postType.HasTaxonomy("category") {
+		if metadata.Category, err = categoryNames(client, post.Categories); err != nil {
+			return metadata, "", fmt.Errorf("カテゴリー取得エラー: %w", err)
+		}
+	}
+	if postType.HasTaxonomy("post_tag") {
+		if metadata.Tag, err = tagNames(client, post.Tags); err != nil {
+			return metadata, "", fmt.Errorf("タグ取得エラー: %w", err)
+		}
+	}
+
+	// 省略時は WordPress 側の値を変更しない項目は、ローカルで指定している場合か既定値でない場合のみ書き戻す
+	if metadata.Author != "" {
+		user, err := GetUser(client, post.Author)
+		if err != nil {
+			return metadata, "", err
+		}
+		metadata.Author = user.Username
+		if metadata.Author == "" {
+			metadata.Author = user.Slug
+		}
+	}
+	if metadata.CommentStatus != "" || post.CommentStatus == "closed" {
+		metadata.CommentStatus = post.CommentStatus
+	}
+	if metadata.PingStatus != "" || post.PingStatus == "closed" {
+		metadata.PingStatus = post.PingStatus
+	}
+	if metadata.Sticky != nil || post.Sticky {
+		sticky := post.Sticky
+		metadata.Sticky = &sticky
+	}
+	if metadata.Format != "" || (post.Format != "" && post.Format != "standard") {
+		metadata.Format = post.Format
+	}
+
+	plugin := detectSEOPluginFromPost(post)
+	if seo := seoFromPost(plugin, post); seo != nil || metadata.SEO != nil {
+		metadata.SEO = seo
+	}
+
+	metadata.Meta = mergeRemoteFields(metadata.Meta, stripSEOMeta(plugin, post.Meta))
+	metadata.ACF = mergeRemoteFields(metadata.ACF, post.ACF)
+
+	metadata.Parent = ""
+	if postType.Hierarchical && post.Parent != 0 {
+		if metadata.Parent, err = postSlug(client, postType, post.Parent); err != nil {
+			return metadata, "", fmt.Errorf("親ページ取得エラー: %w", err)
+		}
+	}
+
+	// 取り込んだ内容を基準に次回の更新で競合とローカルの変更を検出する
+	body := ConvertHTMLToMarkdown(post.Content.Raw)
+	metadata.Sync = NewSyncState(post)
+	metadata.Sync.LocalHash = HashArticle(metadata, body)
+
+	return metadata, body, nil
+}
@@ -0,0 +1,63 @@
+package wp
+
+import "fmt"
+
+// Revision は /wp/v2/{rest_base}/{id}/revisions で取得できるリビジョンです
+type Revision struct {
+	ID          int           `json:"id"`
+	Author      int           `json:"author"`
+	Date        string        `json:"date"`
+	DateGMT     string        `json:"date_gmt"`
+	ModifiedGMT string        `json:"modified_gmt"`
+	Parent      int           `json:"parent"`
+	Title       RenderedField `json:"title"`
+	Content     RenderedField `json:"content"`
+	Excerpt     RenderedField `json:"excerpt"`
+}
+
+// GetRevisions は投稿のリビジョンを新しい順に取得します
+func GetRevisions(client API, restBase string, postID int) ([]Revision, error) {
+	revisions, err := client.Revisions(restBase, postID)
+	if err != nil {
+		return nil, fmt.Errorf("リビジョン取得エラー: %w", err)
+	}
+	return revisions, nil
+}
+
+// GetRevision はリビジョンを1件取得します
+func GetRevision(client API, restBase string, postID, revisionID int) (*Revision, error) {
+	revision, err := client.Revision(restBase, postID, revisionID)
+	if err != nil {
+		return nil, fmt.Errorf("リビジョン取得エラー: %w", err)
+	}
+	return revision, nil
+}
+
+// Revisions は投稿のリビジョンを新しい順にすべてのページにわたって取得します
+func (c *Client) Revisions(restBase string, postID int) ([]Revision, error) {
+	return getAllPages[Revision](c, fmt.Sprintf("/wp-json/wp/v2/%s/%d/revisions?context=edit", restBase, postID))
+}
+
+// Revision はリビジョンを1件取得します
+func (c *Client) Revision(restBase string, postID, revisionID int) (*Revision, error) {
+	var revision Revision
+	path := fmt.Sprintf("/wp-json/wp/v2/%s/%d/revisions/%d?context=edit", restBase, postID, revisionID)
+	if err := getJSON(c, path, &revision); err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// RestoreRevision はリビジョンのタイトル・本文・抜粋で投稿を更新します
+func RestoreRevision(client API, restBase string, postID int, revision *Revision) (*PostResponse, error) {
+	fields := map[string]interface{}{
+		"title":   revision.Title.Raw,
+		"content": revision.Content.Raw,
+		"excerpt": revision.Excerpt.Raw,
+	}
+	post, err := client.UpdatePostFields(restBase, postID, fields)
+	if err != nil {
+		return nil, fmt.Errorf("リビジョン復元エラー: %w", err)
+	}
+	return post, nil
+}
@@ -0,0 +1,181 @@
+package wp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TemplateName は記事のテンプレートのファイル名です。記事を作成するディレクトリから記事の置き場所まで親へ探します。
+// "_" で始まるため記事としては扱いません。
+const TemplateName = "_template.md"
+
+// DefaultTemplate はテンプレートがない場合に使う本文です
+const DefaultTemplate = `## はじめに
+
+<!-- この記事で扱う内容と対象の読者 -->
+
+## 本題
+
+## まとめ
+`
+
+// rangeDir は posts_001-050 のように記事番号の範囲を名前に持つディレクトリです
+var rangeDir = regexp.MustCompile(`^(.*?)(\d+)-(\d+)$`)
+
+// articleRange はディレクトリ名から記事番号の範囲を返します
+func articleRange(dir string) (prefix string, lo, hi int, width int, ok bool) {
+	m := rangeDir.FindStringSubmatch(path.Base(dir))
+	if m == nil {
+		return "", 0, 0, 0, false
+	}
+	lo, _ = strconv.Atoi(m[2])
+	hi, _ = strconv.Atoi(m[3])
+	if lo > hi {
+		return "", 0, 0, 0, false
+	}
+	return m[1], lo, hi, len(m[2]), true
+}
+
+// IsArticleRangeDir は name が posts_001-050 のような記事番号の範囲のディレクトリ名かを返します
+func IsArticleRangeDir(name string) bool {
+	_, _, _, _, ok := articleRange(name)
+	return ok
+}
+
+// NextArticleName はディレクトリ dir に次に作成する記事名（例: posts_001-050/26）を返します。
+// 数字の名前の記事のうち最も大きい番号の次にします。posts_001-050 のように範囲を名前に持つディレクトリでは、
+// 範囲の先頭から数え、範囲を使い切った場合は次の範囲のディレクトリ（posts_051-100）に作成します。
+func NextArticleName(fsys fs.FS, dir string) (string, error) {
+	dir = path.Clean(dir)
+	for {
+		prefix, lo, hi, width, isRange := articleRange(dir)
+		next := 1
+		if isRange {
+			next = lo
+		}
+		entries, err := fs.ReadDir(fsys, dir)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+		for _, e := range entries {
+			if e.IsDir() || path.Ext(e.Name()) != ".md" {
+				continue
+			}
+			if n, err := strconv.Atoi(strings.TrimSuffix(e.Name(), ".md")); err == nil && n >= next {
+				next = n + 1
+			}
+		}
+		if !isRange || next <= hi {
+			return path.Join(dir, strconv.Itoa(next)), nil
+		}
+		size := hi - lo + 1
+		dir = path.Join(path.Dir(dir), fmt.Sprintf("%s%0*d-%0*d", prefix, width, hi+1, width, hi+size))
+	}
+}
+
+// LatestArticleDir は記事の置き場所の直下にある posts_001-050 のような範囲のディレクトリのうち、最も番号の大きいものを返します。
+// ない場合は "." を返します。
+func LatestArticleDir(fsys fs.FS) (string, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", err
+	}
+	latest, latestLo := ".", -1
+	for _, e := range entries {
+		if _, lo, _, _, ok := articleRange(e.Name()); e.IsDir() && ok && lo > latestLo {
+			latest, latestLo = e.Name(), lo
+		}
+	}
+	return latest, nil
+}
+
+// ArticleTemplateFS は記事名 name の記事に使うテンプレートを読み込みます。
+// 記事のディレクトリから記事の置き場所まで親へ TemplateName を探し、ない場合はメタデータが空で本文が DefaultTemplate のテンプレートを返します。
+// テンプレートの投稿IDと同期状態は使いません。
+func ArticleTemplateFS(fsys fs.FS, name string) (ArticleMetadata, string, error) {
+	for dir := path.Dir(path.Clean(name)); ; dir = path.Dir(dir) {
+		content, err := fs.ReadFile(fsys, path.Join(dir, TemplateName))
+		if err == nil {
+			metadata, body, err := ParseArticle(content)
+			if err != nil {
+				return ArticleMetadata{}, "", fmt.Errorf("テンプレート %s: %w", path.Join(dir, TemplateName), err)
+			}
+			return metadata.Clean(), strings.TrimLeft(body, "\n"), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return ArticleMetadata{}, "", err
+		}
+		if dir == "." || dir == "/" {
+			return ArticleMetadata{Tag: []string{}, Category: []string{}}, DefaultTemplate, nil
+		}
+	}
+}
+
+// Clean は投稿IDと同期状態をすべてのプロファイルについて取り除いたメタデータを返します。
+// 既存の記事やテンプレートから新しい記事を作るときに、別の投稿を上書きしないために使います。
+func (m ArticleMetadata) Clean() ArticleMetadata {
+	m.PostID, m.Sync, m.Profiles, m.defaultState = 0, nil, nil, ProfileState{}
+	if m.Tag == nil {
+		m.Tag = []string{}
+	}
+	if m.Category == nil {
+		m.Category = []string{}
+	}
+	return m
+}
+
+// CreateFileFS は既存のファイルを上書きせずにファイルを作成できるファイルシステムです
+type CreateFileFS interface {
+	fs.FS
+	// CreateFile は name にファイルを作成します。ファイルがすでにある場合は fs.ErrExist のエラーを返します。
+	CreateFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// createFile はファイルがない場合だけ作成します
+func createFile(fsys fs.FS, name string, data []byte) error {
+	if c, ok := fsys.(CreateFileFS); ok {
+		return c.CreateFile(name, data, 0644)
+	}
+	if _, err := fs.Stat(fsys, name); err == nil {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return writeFile(fsys, name, data)
+}
+
+// CreateArticleFS は記事ファイルを新しく作成します。
+// 同じ名前の記事がすでにある場合は上書きせず、fs.ErrExist のエラーを返します。
+func CreateArticleFS(fsys fs.FS, filename string, metadata ArticleMetadata, body string) error {
+	mdFilename, err := articlePath(filename)
+	if err != nil {
+		return err
+	}
+	content, err := formatArticle(metadata, body)
+	if err != nil {
+		return err
+	}
+	if err := createFile(fsys, mdFilename, content); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return &articleExistsError{name: filename}
+		}
+		return fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// articleExistsError は作成しようとした記事がすでにあることを表すエラーです。errors.Is で fs.ErrExist と判定できます。
+type articleExistsError struct {
+	name string
+}
+
+func (e *articleExistsError) Error() string {
+	return fmt.Sprintf("記事がすでにあります（上書きしません）: %s", e.name)
+}
+
+func (e *articleExistsError) Is(target error) bool { return target == fs.ErrExist }
@@ -0,0 +1,267 @@
+package wp
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// SchemaProperty は REST API のスキーマ（JSON Schema）のプロパティ定義です
+type SchemaProperty struct {
+	Type       interface{}               `json:"type"`
+	Enum       []interface{}             `json:"enum,omitempty"`
+	Items      *SchemaProperty           `json:"items,omitempty"`
+	Properties map[string]SchemaProperty `json:"properties,omitempty"`
+	ReadOnly   bool                      `json:"readonly,omitempty"`
+}
+
+// PostSchema は OPTIONS /wp/v2/{rest_base} で取得できる投稿のスキーマです
+type PostSchema struct {
+	Properties map[string]SchemaProperty `json:"properties"`
+}
+
+// GetPostSchema は投稿タイプのスキーマを取得します
+func GetPostSchema(client API, restBase string) (*PostSchema, error) {
+	schema, err := client.PostSchema(restBase)
+	if err != nil {
+		return nil, fmt.Errorf("スキーマ取得エラー: %w", err)
+	}
+	return schema, nil
+}
+
+// PostSchema は投稿タイプのスキーマを取得し、キャッシュします
+func (c *Client) PostSchema(restBase string) (*PostSchema, error) {
+	c.Cache.mu.Lock()
+	defer c.Cache.mu.Unlock()
+
+	if schema, ok := c.Cache.schemas[restBase]; ok {
+		return schema, nil
+	}
+
+	url := c.BaseURL + "/wp-json/wp/v2/" + restBase
+	req, err := http.NewRequest("OPTIONS", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var options struct {
+		Schema PostSchema `json:"schema"`
+	}
+	if err := c.decodeResponse(resp, &options); err != nil {
+		return nil, err
+	}
+
+	if c.Cache.schemas == nil {
+		c.Cache.schemas = make(map[string]*PostSchema)
+	}
+	c.Cache.schemas[restBase] = &options.Schema
+	return &options.Schema, nil
+}
+
+// MetaFields は REST に公開されているポストメタの定義を返します
+func (s *PostSchema) MetaFields() map[string]SchemaProperty {
+	return s.Properties["meta"].Properties
+}
+
+// HasACF は ACF プラグインの REST フィールドが有効かどうかを返します
+func (s *PostSchema) HasACF() bool {
+	_, ok := s.Properties["acf"]
+	return ok
+}
+
+// ValidateFields はメタデータの Meta と ACF をスキーマに照らして検証します
+func ValidateFields(schema *PostSchema, metadata ArticleMetadata) error {
+	var errs []error
+
+	metaFields := schema.MetaFields()
+	for _, key := range sortedKeys(metadata.Meta) {
+		prop, ok := metaFields[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("Meta.%s: REST APIに登録されていないメタキーです（register_post_meta で show_in_rest を有効にしてください）", key))
+			continue
+		}
+		if err := prop.validate(metadata.Meta[key]); err != nil {
+			errs = append(errs, fmt.Errorf("Meta.%s: %w", key, err))
+		}
+	}
+
+	if len(metadata.ACF) > 0 {
+		if !schema.HasACF() {
+			errs = append(errs, fmt.Errorf("ACF: サイトでACFのREST APIが有効になっていません"))
+		} else {
+			// ACF はフィールドグループの設定によってスキーマにフィールドが含まれない場合があるため、
+			// 定義がある場合のみ型を検証する
+			acfFields := schema.Properties["acf"].Properties
+			for _, key := range sortedKeys(metadata.ACF) {
+				if prop, ok := acfFields[key]; ok {
+					if err := prop.validate(metadata.ACF[key]); err != nil {
+						errs = append(errs, fmt.Errorf("ACF.%s: %w", key, err))
+					}
+				}
+			}
+		}
+	}
+
+	return invalidArticle(errors.Join(errs...))
+}
+
+// validate は値がプロパティの型と列挙値に合っているかを検証します
+func (p SchemaProperty) validate(value interface{}) error {
+	if p.ReadOnly {
+		return fmt.Errorf("読み取り専用のフィールドです")
+	}
+
+	types := p.types()
+	if len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesType(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("型が一致しません（期待: %s、実際: %s）", strings.Join(types, "|"), jsonTypeOf(value))
+		}
+	}
+
+	if len(p.Enum) > 0 {
+		found := false
+		for _, e := range p.Enum {
+			if fmt.Sprint(e) == fmt.Sprint(value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("許可されていない値です: %v（候補: %v）", value, p.Enum)
+		}
+	}
+
+	if items, ok := value.([]interface{}); ok && p.Items != nil {
+		for i, item := range items {
+			if err := p.Items.validate(item); err != nil {
+				return fmt.Errorf("[%d]: %w", i, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// types はスキーマの type（文字列または配列）を文字列のスライスで返します
+func (p SchemaProperty) types() []string {
+	switch t := p.Type.(type) {
+	case string:
+		return []string{t}
+	case []interface{}:
+		var types []string
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+// matchesType は JSON をデコードした値が JSON Schema の型に一致するかを返します
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "null":
+		return value == nil
+	}
+	return true
+}
+
+func jsonTypeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergeRemoteFields はローカルにあるキーと、サーバー側で値が設定されているキーをサーバーの値で更新します
+func mergeRemoteFields(local map[string]interface{}, remote FieldMap) map[string]interface{} {
+	merged := make(map[string]interface{})
+	for key, value := range remote {
+		_, tracked := local[key]
+		if tracked || !isEmptyValue(value) {
+			merged[key] = value
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// isEmptyValue は WordPress が未設定のメタに返す既定値（空文字、0、false、空配列）かどうかを返します
+func isEmptyValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case float64:
+		return v == 0
+	case bool:
+		return !v
+	case []interface{}:
+		return len(v) == 0
+	case map[string]interface{}:
+		return len(v) == 0
+	}
+	return false
+}
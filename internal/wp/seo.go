@@ -0,0 +1,279 @@
+package wp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// SEOPlugin はサイトで有効な SEO プラグインの種類です
+type SEOPlugin string
+
+const (
+	SEOPluginNone     SEOPlugin = ""
+	SEOPluginYoast    SEOPlugin = "yoast"
+	SEOPluginRankMath SEOPlugin = "rank-math"
+	SEOPluginAIOSEO   SEOPlugin = "aioseo"
+)
+
+// 日本語の検索結果で省略されずに表示される目安（全角換算）
+const (
+	seoTitleMaxWidth       = 32
+	seoDescriptionMinWidth = 50
+	seoDescriptionMaxWidth = 120
+)
+
+// seoMetaKeys は SEO プラグインごとのポストメタのキーです
+type seoMetaKeys struct {
+	Title, Description, FocusKeyword, Canonical, NoIndex, OGImage, OGImageID string
+}
+
+var seoPluginMetaKeys = map[SEOPlugin]seoMetaKeys{
+	SEOPluginYoast: {
+		Title:        "_yoast_wpseo_title",
+		Description:  "_yoast_wpseo_metadesc",
+		FocusKeyword: "_yoast_wpseo_focuskw",
+		Canonical:    "_yoast_wpseo_canonical",
+		NoIndex:      "_yoast_wpseo_meta-robots-noindex",
+		OGImage:      "_yoast_wpseo_opengraph-image",
+		OGImageID:    "_yoast_wpseo_opengraph-image-id",
+	},
+	SEOPluginRankMath: {
+		Title:        "rank_math_title",
+		Description:  "rank_math_description",
+		FocusKeyword: "rank_math_focus_keyword",
+		Canonical:    "rank_math_canonical_url",
+		NoIndex:      "rank_math_robots",
+		OGImage:      "rank_math_facebook_image",
+		OGImageID:    "rank_math_facebook_image_id",
+	},
+}
+
+// DetectSEOPlugin はスキーマに登録されているメタキーと REST フィールドから SEO プラグインを判定します
+func DetectSEOPlugin(schema *PostSchema) (SEOPlugin, error) {
+	metaFields := schema.MetaFields()
+	for _, plugin := range []SEOPlugin{SEOPluginYoast, SEOPluginRankMath} {
+		if _, ok := metaFields[seoPluginMetaKeys[plugin].Description]; ok {
+			return plugin, nil
+		}
+	}
+	if _, ok := schema.Properties["aioseo_meta_data"]; ok {
+		return SEOPluginAIOSEO, nil
+	}
+	if _, ok := schema.Properties["yoast_head_json"]; ok {
+		return SEOPluginNone, fmt.Errorf("Yoast SEO は有効ですが、%s などのメタキーが REST API に登録されていません", seoPluginMetaKeys[SEOPluginYoast].Description)
+	}
+	return SEOPluginNone, fmt.Errorf("対応している SEO プラグイン（Yoast SEO、Rank Math、All in One SEO）が見つかりません")
+}
+
+// detectSEOPluginFromPost は取得した投稿に含まれるフィールドから SEO プラグインを判定します
+func detectSEOPluginFromPost(post *PostResponse) SEOPlugin {
+	for _, plugin := range []SEOPlugin{SEOPluginYoast, SEOPluginRankMath} {
+		if _, ok := post.Meta[seoPluginMetaKeys[plugin].Description]; ok {
+			return plugin
+		}
+	}
+	if post.AIOSEO != nil {
+		return SEOPluginAIOSEO
+	}
+	return SEOPluginNone
+}
+
+// ValidateSEO は SEO 項目を検証します。
+// 形式の誤りはエラーとして返し、推奨される文字数を外れている場合は警告として返します。
+func ValidateSEO(seo *SEOMetadata) (warnings []string, err error) {
+	if seo.Canonical != "" {
+		u, parseErr := url.Parse(seo.Canonical)
+		if parseErr != nil || !u.IsAbs() {
+			return nil, fmt.Errorf("SEO.Canonical は絶対URLで指定してください: %s", seo.Canonical)
+		}
+	}
+
+	if seo.Title != "" {
+		if w := displayWidth(seo.Title); w > seoTitleMaxWidth {
+			warnings = append(warnings, fmt.Sprintf("SEO.Title が長すぎます（全角換算 %.1f 文字、推奨 %d 文字以内）", w, seoTitleMaxWidth))
+		}
+	}
+	if seo.Description != "" {
+		w := displayWidth(seo.Description)
+		if w > seoDescriptionMaxWidth {
+			warnings = append(warnings, fmt.Sprintf("SEO.Description が長すぎます（全角換算 %.1f 文字、推奨 %d 文字以内）", w, seoDescriptionMaxWidth))
+		} else if w < seoDescriptionMinWidth {
+			warnings = append(warnings, fmt.Sprintf("SEO.Description が短すぎます（全角換算 %.1f 文字、推奨 %d 文字以上）", w, seoDescriptionMinWidth))
+		}
+	}
+	if seo.FocusKeyword != "" && !strings.Contains(seo.Title, seo.FocusKeyword) && !strings.Contains(seo.Description, seo.FocusKeyword) {
+		warnings = append(warnings, fmt.Sprintf("SEO.FocusKeyword「%s」がタイトルにも説明文にも含まれていません", seo.FocusKeyword))
+	}
+
+	return warnings, nil
+}
+
+// displayWidth は半角文字を 0.5、それ以外を 1 として全角換算の文字数を返します
+func displayWidth(s string) float64 {
+	var width float64
+	for _, r := range s {
+		if utf8.RuneLen(r) == 1 || (r >= 0xFF61 && r <= 0xFF9F) {
+			width += 0.5
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// ApplySEO は SEO 項目をプラグインに応じたメタまたは REST フィールドとして投稿リクエストに設定します。
+// ogImage は OGImage をアップロードした結果で、OGImage が URL の場合は nil です。
+func ApplySEO(post *PostRequest, plugin SEOPlugin, schema *PostSchema, seo *SEOMetadata, ogImage *MediaResponse) error {
+	ogImageURL := seo.OGImage
+	ogImageID := 0
+	if ogImage != nil {
+		ogImageURL = ogImage.URL
+		ogImageID = ogImage.ID
+	}
+
+	if plugin == SEOPluginAIOSEO {
+		fields := map[string]interface{}{}
+		setIfNotEmpty(fields, "title", seo.Title)
+		setIfNotEmpty(fields, "description", seo.Description)
+		setIfNotEmpty(fields, "canonical_url", seo.Canonical)
+		fields["robots_default"] = !seo.NoIndex
+		fields["robots_noindex"] = seo.NoIndex
+		if ogImageURL != "" {
+			fields["og_image_type"] = "custom_image"
+			fields["og_image_custom_url"] = ogImageURL
+		}
+		if seo.FocusKeyword != "" {
+			fields["keyphrases"] = map[string]interface{}{
+				"focus": map[string]interface{}{"keyphrase": seo.FocusKeyword},
+			}
+		}
+		post.AIOSEO = fields
+		return nil
+	}
+
+	keys, ok := seoPluginMetaKeys[plugin]
+	if !ok {
+		return fmt.Errorf("未対応の SEO プラグインです: %s", plugin)
+	}
+
+	meta := make(map[string]interface{}, len(post.Meta))
+	for k, v := range post.Meta {
+		meta[k] = v
+	}
+	registered := schema.MetaFields()
+	var missing []string
+	set := func(key string, value interface{}) {
+		if _, ok := registered[key]; !ok {
+			missing = append(missing, key)
+			return
+		}
+		meta[key] = value
+	}
+
+	if seo.Title != "" {
+		set(keys.Title, seo.Title)
+	}
+	if seo.Description != "" {
+		set(keys.Description, seo.Description)
+	}
+	if seo.FocusKeyword != "" {
+		set(keys.FocusKeyword, seo.FocusKeyword)
+	}
+	if seo.Canonical != "" {
+		set(keys.Canonical, seo.Canonical)
+	}
+	if plugin == SEOPluginRankMath {
+		robots := []interface{}{"index"}
+		if seo.NoIndex {
+			robots = []interface{}{"noindex"}
+		}
+		set(keys.NoIndex, robots)
+	} else {
+		noindex := "0"
+		if seo.NoIndex {
+			noindex = "1"
+		}
+		set(keys.NoIndex, noindex)
+	}
+	if ogImageURL != "" {
+		set(keys.OGImage, ogImageURL)
+		if ogImageID != 0 {
+			if _, ok := registered[keys.OGImageID]; ok {
+				meta[keys.OGImageID] = fmt.Sprint(ogImageID)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("SEO プラグイン %s のメタキーが REST API に登録されていません: %s", plugin, strings.Join(missing, ", "))
+	}
+	post.Meta = meta
+	return nil
+}
+
+func setIfNotEmpty(fields map[string]interface{}, key, value string) {
+	if value != "" {
+		fields[key] = value
+	}
+}
+
+// seoFromPost は投稿に設定されている SEO 項目を読み取ります。設定がない場合は nil を返します。
+func seoFromPost(plugin SEOPlugin, post *PostResponse) *SEOMetadata {
+	var seo SEOMetadata
+	switch plugin {
+	case SEOPluginAIOSEO:
+		seo.Title, _ = post.AIOSEO["title"].(string)
+		seo.Description, _ = post.AIOSEO["description"].(string)
+		seo.Canonical, _ = post.AIOSEO["canonical_url"].(string)
+		seo.NoIndex, _ = post.AIOSEO["robots_noindex"].(bool)
+		seo.OGImage, _ = post.AIOSEO["og_image_custom_url"].(string)
+		if keyphrases, ok := post.AIOSEO["keyphrases"].(map[string]interface{}); ok {
+			if focus, ok := keyphrases["focus"].(map[string]interface{}); ok {
+				seo.FocusKeyword, _ = focus["keyphrase"].(string)
+			}
+		}
+	case SEOPluginYoast, SEOPluginRankMath:
+		keys := seoPluginMetaKeys[plugin]
+		seo.Title, _ = post.Meta[keys.Title].(string)
+		seo.Description, _ = post.Meta[keys.Description].(string)
+		seo.FocusKeyword, _ = post.Meta[keys.FocusKeyword].(string)
+		seo.Canonical, _ = post.Meta[keys.Canonical].(string)
+		seo.OGImage, _ = post.Meta[keys.OGImage].(string)
+		switch v := post.Meta[keys.NoIndex].(type) {
+		case string:
+			seo.NoIndex = v == "1"
+		case []interface{}:
+			for _, r := range v {
+				if r == "noindex" {
+					seo.NoIndex = true
+				}
+			}
+		}
+	default:
+		return nil
+	}
+
+	if seo == (SEOMetadata{}) {
+		return nil
+	}
+	return &seo
+}
+
+// stripSEOMeta は SEO 項目として扱うメタキーを取り除きます
+func stripSEOMeta(plugin SEOPlugin, meta FieldMap) FieldMap {
+	keys, ok := seoPluginMetaKeys[plugin]
+	if !ok {
+		return meta
+	}
+	stripped := FieldMap{}
+	for k, v := range meta {
+		switch k {
+		case keys.Title, keys.Description, keys.FocusKeyword, keys.Canonical, keys.NoIndex, keys.OGImage, keys.OGImageID:
+			continue
+		}
+		stripped[k] = v
+	}
+	return stripped
+}
@@ -0,0 +1,174 @@
+package wp
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxSlugLength は SuggestSlug が提案するスラッグの最大の長さです
+const maxSlugLength = 60
+
+// kanaRomaji はひらがなのヘボン式ローマ字です
+var kanaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'を': "o", 'ん': "n", 'ゔ': "vu",
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa",
+}
+
+// 単語の種類
+const (
+	wordASCII = iota
+	wordHiragana
+)
+
+// SuggestSlug はタイトルからスラッグ（例: laravel-cors-error）を提案します。
+// 英数字の単語をつなげます。ひらがなは助詞や送り仮名が多いため、ほかに単語がないタイトルの場合だけヘボン式のローマ字にして使います。
+// 漢字は読みが分からず、カタカナの外来語は音節ごとのローマ字（エラー → era）では元の単語にならないため、
+// 漢字とカタカナは単語の区切りとして扱います。英数字の単語がなく漢字またはカタカナを含むタイトルは空文字列を返します。
+func SuggestSlug(title string) string {
+	type word struct {
+		kind int
+		text []rune
+	}
+	var words []word
+	hasKanji := false
+	for _, r := range title {
+		// 全角英数字は半角に直す
+		if r >= '！' && r <= '～' {
+			r -= '！' - '!'
+		}
+		kind := -1
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			kind = wordASCII
+			r = unicode.ToLower(r)
+		case unicode.In(r, unicode.Han, unicode.Katakana):
+			hasKanji = true
+		case unicode.In(r, unicode.Hiragana) || r == 'ー':
+			kind = wordHiragana
+		}
+		if kind < 0 {
+			words = append(words, word{kind: -1})
+			continue
+		}
+		if n := len(words); n > 0 && words[n-1].kind == kind {
+			words[n-1].text = append(words[n-1].text, r)
+		} else {
+			words = append(words, word{kind: kind, text: []rune{r}})
+		}
+	}
+
+	var parts, hiragana []string
+	for _, w := range words {
+		switch w.kind {
+		case wordASCII:
+			parts = append(parts, string(w.text))
+		case wordHiragana:
+			hiragana = appendNonEmpty(hiragana, romanize(w.text))
+		}
+	}
+	if len(parts) == 0 && !hasKanji {
+		parts = hiragana
+	}
+
+	var slug string
+	for _, part := range parts {
+		if slug != "" && len(slug)+1+len(part) > maxSlugLength {
+			break
+		}
+		if slug != "" {
+			slug += "-"
+		}
+		slug += part
+	}
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+	}
+	return slug
+}
+
+func appendNonEmpty(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	return append(values, value)
+}
+
+// romanize はひらがなをヘボン式のローマ字にします。長音符は省き、促音は次の子音を重ねます。
+func romanize(kana []rune) string {
+	var b strings.Builder
+	var prev string
+	sokuon := false
+	flush := func() {
+		b.WriteString(prev)
+		prev = ""
+	}
+	for _, r := range kana {
+		switch r {
+		case 'ー':
+			continue
+		case 'っ':
+			flush()
+			sokuon = true
+			continue
+		case 'ゃ', 'ゅ', 'ょ':
+			// 拗音: きゃ → kya、しゃ → sha
+			if stem, ok := trimVowel(prev); ok {
+				vowel := kanaRomaji[r][1:]
+				if stem == "sh" || stem == "ch" || stem == "j" {
+					prev = stem + vowel
+				} else {
+					prev = stem + "y" + vowel
+				}
+				continue
+			}
+		case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+			// 小さい母音: ふぁ → fa、てぃ → ti、うぃ → wi
+			if stem, ok := trimVowel(prev); ok {
+				if stem == "" {
+					stem = "w"
+				}
+				prev = stem + kanaRomaji[r]
+				continue
+			}
+		}
+		romaji, ok := kanaRomaji[r]
+		if !ok {
+			continue
+		}
+		flush()
+		if sokuon {
+			if romaji[0] == 'c' {
+				b.WriteByte('t')
+			} else if !strings.ContainsRune("aiueon", rune(romaji[0])) {
+				b.WriteByte(romaji[0])
+			}
+			sokuon = false
+		}
+		prev = romaji
+	}
+	flush()
+	return b.String()
+}
+
+// trimVowel はローマ字の音節から最後の母音を除いた子音を返します。ん などの母音で終わらない音節は ok が false です。
+func trimVowel(syllable string) (string, bool) {
+	if syllable == "" || syllable == "n" || !strings.ContainsRune("aiueo", rune(syllable[len(syllable)-1])) {
+		return "", false
+	}
+	return syllable[:len(syllable)-1], true
+}
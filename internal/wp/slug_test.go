@@ -0,0 +1,32 @@
+package wp_test
+
+import (
+	"testing"
+
+	"wp/internal/wp"
+)
+
+func TestSuggestSlug(t *testing.T) {
+	tests := []struct {
+		title, want string
+	}{
+		{"Laravel CORS Error", "laravel-cors-error"},
+		{"Ｇｏ 1.22 release", "go-1-22-release"},
+		{"Go のはじめかた", "go"},
+		{"ちょっとした こつ", "chottoshita-kotsu"},
+		// 漢字とカタカナは読みや元の英単語が分からないため、英数字の単語だけを使う
+		{"LaravelでCORSエラー", "laravel-cors"},
+		{"Laravel で「CORS error」が出るときの対処法", "laravel-cors-error"},
+		{"Go 入門", "go"},
+		{"はじめての Go 入門", "go"},
+		// 英数字の単語がなければ提案しない
+		{"エラー", ""},
+		{"はじめての入門", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := wp.SuggestSlug(tt.title); got != tt.want {
+			t.Errorf("SuggestSlug(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
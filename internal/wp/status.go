@@ -0,0 +1,185 @@
+package wp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ローカルの記事の状態
+const (
+	LocalNew       = "new"       // まだ投稿されていない
+	LocalUnchanged = "unchanged" // 最後の投稿・取得から変更されていない
+	LocalModified  = "modified"  // 最後の投稿・取得から変更されている
+	LocalUnknown   = "unknown"   // 同期状態が記録されていないため判定できない
+)
+
+// RemoteDeleted は post_id の投稿が WordPress 上に見つからないことを表すステータスです
+const RemoteDeleted = "deleted"
+
+// ArticleStatus は記事ファイル1つの同期状態です
+type ArticleStatus struct {
+	File   string `json:"file"`
+	PostID int    `json:"post_id,omitempty"`
+	Type   string `json:"type"`
+	// RemoteStatus は WordPress 上のステータス（publish、draft など）です。未投稿の場合は空です。
+	RemoteStatus string `json:"remote_status,omitempty"`
+	// RemoteChanged は最後の投稿・取得以降に WordPress 上で変更された場合に true です
+	RemoteChanged  bool      `json:"remote_changed"`
+	RemoteModified string    `json:"remote_modified,omitempty"`
+	Local          string    `json:"local"`
+	LocalModified  time.Time `json:"local_modified"`
+	Link           string    `json:"link,omitempty"`
+}
+
+// HashArticle はローカルの記事の変更を検出するためのハッシュを返します。
+// 同期状態（sync）自体はハッシュに含めません。
+func HashArticle(metadata ArticleMetadata, body string) string {
+	// 投稿IDと同期状態はプロファイルによって異なるため、ファイルに書き込む形で既定のプロファイルの投稿IDだけを含める
+	metadata = metadata.fileForm()
+	metadata.Sync = nil
+	metadata.Profiles = nil
+	data, _ := json.Marshal(metadata)
+	sum := sha256.Sum256(append(data, []byte(normalizeContent(body))...))
+	return hex.EncodeToString(sum[:])
+}
+
+// ListArticles は DefaultWorkspace の記事を一覧します
+func ListArticles() ([]string, error) {
+	return ListArticlesFS(DefaultWorkspace.Articles)
+}
+
+// ListArticlesFS はファイルシステム以下の記事を、拡張子を除いた名前（例: posts_001-050/1）で返します。
+// "." や "_" で始まるファイル・ディレクトリは記事として扱いません。
+func ListArticlesFS(fsys fs.FS) ([]string, error) {
+	var names []string
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		base := d.Name()
+		if p != "." && (strings.HasPrefix(base, ".") || strings.HasPrefix(base, "_")) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || path.Ext(base) != ".md" {
+			return nil
+		}
+		names = append(names, strings.TrimSuffix(p, ".md"))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("記事一覧の取得エラー: %w", err)
+	}
+	sortArticleNames(names)
+	return names, nil
+}
+
+// sortArticleNames はディレクトリごとに、ファイル名の数値順（1, 2, ..., 10）で並べ替えます
+func sortArticleNames(names []string) {
+	sort.Slice(names, func(i, j int) bool {
+		di, fi := path.Split(names[i])
+		dj, fj := path.Split(names[j])
+		if di != dj {
+			return di < dj
+		}
+		ni, errI := strconv.Atoi(fi)
+		nj, errJ := strconv.Atoi(fj)
+		if errI == nil && errJ == nil {
+			return ni < nj
+		}
+		return fi < fj
+	})
+}
+
+// CollectStatus は置き場所 ws の記事ごとの同期状態を集めます。
+// WordPress 上の状態は投稿タイプごとに include で最大100件ずつまとめて取得します。
+func CollectStatus(client API, ws *Workspace, names []string) ([]ArticleStatus, error) {
+	statuses := make([]ArticleStatus, len(names))
+	recorded := make([]string, len(names)) // 最後の投稿・取得時の modified_gmt
+	byType := make(map[string][]int)       // REST base → statuses のインデックス
+
+	for i, name := range names {
+		metadata, body, err := ws.ReadArticle(name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		info, err := fs.Stat(ws.Articles, name+".md")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+
+		st := ArticleStatus{File: name, PostID: metadata.PostID, LocalModified: info.ModTime()}
+		switch {
+		case metadata.PostID == 0:
+			st.Local = LocalNew
+		case metadata.Sync == nil || metadata.Sync.LocalHash == "":
+			st.Local = LocalUnknown
+		case metadata.Sync.LocalHash == HashArticle(metadata, body):
+			st.Local = LocalUnchanged
+		default:
+			st.Local = LocalModified
+		}
+
+		postType, err := ResolvePostType(client, metadata.Type)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		st.Type = postType.Slug
+		statuses[i] = st
+
+		if metadata.PostID != 0 {
+			byType[postType.RestBase] = append(byType[postType.RestBase], i)
+		}
+		if metadata.Sync != nil {
+			recorded[i] = metadata.Sync.ModifiedGMT
+		}
+	}
+
+	for restBase, indexes := range byType {
+		for start := 0; start < len(indexes); start += 100 {
+			chunk := indexes[start:min(start+100, len(indexes))]
+			ids := make([]int, len(chunk))
+			for k, i := range chunk {
+				ids[k] = statuses[i].PostID
+			}
+
+			posts, err := client.ListPosts(restBase, PostQuery{
+				Include: ids,
+				Status:  []string{"publish", "future", "draft", "pending", "private", "trash"},
+				Fields:  []string{"id", "status", "modified_gmt", "link"},
+			})
+			if err != nil {
+				return nil, fmt.Errorf("投稿一覧の取得エラー: %w", err)
+			}
+
+			found := make(map[int]PostResponse, len(posts))
+			for _, p := range posts {
+				found[p.ID] = p
+			}
+			for _, i := range chunk {
+				st := &statuses[i]
+				post, ok := found[st.PostID]
+				if !ok {
+					st.RemoteStatus = RemoteDeleted
+					continue
+				}
+				st.RemoteStatus = post.Status
+				st.RemoteModified = post.ModifiedGMT
+				st.RemoteChanged = recorded[i] != "" && recorded[i] != post.ModifiedGMT
+				st.Link = post.Link
+			}
+		}
+	}
+
+	return statuses, nil
+}
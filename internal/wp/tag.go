@@ -5,74 +5,52 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strings"
 )
 
-func CreateTag(client *Client, name string) (*Tag, error) {
+func CreateTag(client API, name string) (*Tag, error) {
+	return client.CreateTag(name)
+}
+
+// CreateTag はタグを作成し、取得済みのタグ一覧に追加します
+func (c *Client) CreateTag(name string) (*Tag, error) {
 	tagReq := CreateTagRequest{
 		Name: name,
 	}
 
 	jsonData, err := json.Marshal(tagReq)
 	if err != nil {
-		return nil, fmt.Errorf("JSON変換エラー: %v", err)
+		return nil, fmt.Errorf("JSON変換エラー: %w", err)
 	}
 
-	url := client.BaseURL + "/wp-json/wp/v2/tags"
+	url := c.BaseURL + "/wp-json/wp/v2/tags"
 	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+client.BasicAuth)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := client.HTTPClient.Do(req)
+	resp, err := c.do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	// エラーレスポンスの詳細を取得
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		var errorResp struct {
-			Code    string `json:"code"`
-			Message string `json:"message"`
-		}
-		if err := json.NewDecoder(resp.Body).Decode(&errorResp); err != nil {
-			return nil, fmt.Errorf("APIエラー: %d", resp.StatusCode)
-		}
-		return nil, fmt.Errorf("APIエラー: %s - %s", errorResp.Code, errorResp.Message)
-	}
-
 	var tag Tag
-	if err := json.NewDecoder(resp.Body).Decode(&tag); err != nil {
+	if err := c.decodeResponse(resp, &tag); err != nil {
 		return nil, err
 	}
+	c.Cache.addTag(tag)
 
 	return &tag, nil
 }
 
-func GetTagID(client *Client, tagName string) (int, error) {
-	url := client.BaseURL + "/wp-json/wp/v2/tags"
-	req, err := http.NewRequest("GET", url, nil)
+func GetTagID(client API, tagName string) (int, error) {
+	tags, err := client.Tags()
 	if err != nil {
 		return 0, err
 	}
 
-	req.Header.Set("Authorization", "Basic "+client.BasicAuth)
-
-	resp, err := client.HTTPClient.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	var tags []Tag
-	if err := client.decodeResponse(resp, &tags); err != nil {
-		return 0, err
-	}
-
 	// 既存のタグを検索
 	for _, tag := range tags {
 		if tag.Name == tagName {
@@ -81,65 +59,60 @@ func GetTagID(client *Client, tagName string) (int, error) {
 	}
 
 	// タグが見つからない場合は新規作成
-	newTag, err := CreateTag(client, tagName)
+	newTag, err := client.CreateTag(tagName)
 	if err != nil {
-		return 0, fmt.Errorf("タグ作成エラー: %v", err)
+		if id, ok := existingTermID(err); ok {
+			return id, nil
+		}
+		return 0, fmt.Errorf("タグ作成エラー: %w", err)
 	}
 
 	return newTag.ID, nil
 }
 
-func GetTagIDs(client *Client, tagNames []string) ([]int, error) {
-	url := client.BaseURL + "/wp-json/wp/v2/tags?per_page=100"  // 取得件数を増やす
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Authorization", "Basic "+client.BasicAuth)
+func GetTagIDs(client API, tagNames []string) ([]int, error) {
+	ids, _, err := ResolveTags(client, tagNames)
+	return ids, err
+}
 
-	resp, err := client.HTTPClient.Do(req)
+// ResolveTags はタグ名をIDに変換し、存在しないタグは作成します。作成したタグも返します。
+func ResolveTags(client API, tagNames []string) ([]int, []Tag, error) {
+	tags, err := client.Tags()
 	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	var tags []Tag
-	if err := client.decodeResponse(resp, &tags); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	var tagIDs []int
+	var created []Tag
 	for _, name := range tagNames {
-		found := false
-		for _, tag := range tags {
-			if strings.EqualFold(tag.Name, name) {  // 大文字小文字を区別しない比較
-				tagIDs = append(tagIDs, tag.ID)
-				found = true
-				break
-			}
+		if tag, ok := findTag(tags, name); ok { // 大文字小文字を区別しない比較
+			tagIDs = append(tagIDs, tag.ID)
+			continue
 		}
 
-		if !found {
-			// タグが存在しない場合は新規作成
-			newTag, err := CreateTag(client, name)
-			if err != nil {
-				// 作成に失敗した場合は、もう一度検索を試みる
-				for _, tag := range tags {
-					if strings.EqualFold(tag.Name, name) {
-						tagIDs = append(tagIDs, tag.ID)
-						found = true
-						break
-					}
-				}
-				if !found {
-					return nil, fmt.Errorf("タグ作成エラー: %v", err)
-				}
-			} else {
-				tagIDs = append(tagIDs, newTag.ID)
+		// タグが存在しない場合は新規作成
+		newTag, err := client.CreateTag(name)
+		if err != nil {
+			if id, ok := existingTermID(err); ok {
+				tagIDs = append(tagIDs, id)
+				continue
+			}
+			// 作成に失敗した場合は、他で作成された可能性があるので取得し直して検索する
+			client.SharedCache().invalidateTerms()
+			tags, listErr := client.Tags()
+			if listErr != nil {
+				return nil, nil, fmt.Errorf("タグ作成エラー: %w", err)
+			}
+			tag, ok := findTag(tags, name)
+			if !ok {
+				return nil, nil, fmt.Errorf("タグ作成エラー: %w", err)
 			}
+			tagIDs = append(tagIDs, tag.ID)
+			continue
 		}
+		tagIDs = append(tagIDs, newTag.ID)
+		created = append(created, *newTag)
 	}
 
-	return tagIDs, nil
+	return tagIDs, created, nil
 }
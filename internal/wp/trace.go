@@ -0,0 +1,100 @@
+package wp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"unicode/utf8"
+)
+
+// traceBodyLimit は TracingTransport が詳細表示で出力する本文の最大バイト数です
+const traceBodyLimit = 2048
+
+// TracingTransport はリクエストごとにメソッド、URL、ステータス、所要時間を Logger に出力する http.RoundTripper です。
+// Debug レベルが有効な場合は、認証情報を伏せたヘッダーと先頭 2KB までの本文も出力します。
+type TracingTransport struct {
+	// Transport は実際にリクエストを送信するトランスポートです。nil の場合は http.DefaultTransport を使います。
+	Transport http.RoundTripper
+	Logger    *slog.Logger
+}
+
+func (t *TracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	transport := t.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	ctx := req.Context()
+	detailed := t.Logger.Enabled(ctx, slog.LevelDebug)
+
+	if detailed {
+		var body []byte
+		if req.Body != nil {
+			data, err := io.ReadAll(req.Body)
+			req.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+			body = data
+			req.Body = io.NopCloser(bytes.NewReader(data))
+		}
+		t.Logger.DebugContext(ctx, "HTTP リクエスト",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"header", redactHeader(req.Header),
+			"body", traceBody(body))
+	}
+
+	start := time.Now()
+	resp, err := transport.RoundTrip(req)
+	duration := time.Since(start)
+	if err != nil {
+		t.Logger.WarnContext(ctx, "HTTP エラー", "method", req.Method, "url", req.URL.String(), "duration", duration, "error", err)
+		return nil, err
+	}
+
+	level := slog.LevelInfo
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	t.Logger.Log(ctx, level, "HTTP",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", resp.StatusCode,
+		"duration", duration)
+
+	if detailed {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		t.Logger.DebugContext(ctx, "HTTP レスポンス",
+			"status", resp.StatusCode,
+			"header", redactHeader(resp.Header),
+			"body", traceBody(body))
+	}
+	return resp, nil
+}
+
+// traceBody は本文を認証情報を伏せて先頭 traceBodyLimit バイトまでにします。バイナリの場合はバイト数だけを返します。
+func traceBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("（バイナリ %d バイト）", len(body))
+	}
+	text := string(redactBody(body))
+	if len(text) <= traceBodyLimit {
+		return text
+	}
+	cut := traceBodyLimit
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…（%d バイト）", text[:cut], len(text))
+}
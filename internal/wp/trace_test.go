@@ -0,0 +1,112 @@
+package wp_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"wp/internal/wp"
+)
+
+// traceRecords は JSON ハンドラーの出力を1行ずつのレコードにします
+func traceRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("%v: %s", err, line)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestTracingTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(time.Millisecond)
+		http.SetCookie(w, &http.Cookie{Name: "wordpress_logged_in_abc", Value: "secret-session"})
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"token": "secret-jwt-token"}`))
+	}))
+	defer server.Close()
+
+	for _, level := range []slog.Level{slog.LevelInfo, slog.LevelDebug} {
+		var buf bytes.Buffer
+		transport := &wp.TracingTransport{Logger: slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))}
+		client := &http.Client{Transport: transport}
+
+		req, err := http.NewRequest("POST", server.URL+"/wp-json/jwt-auth/v1/token", strings.NewReader(`{"username": "admin", "password": "secret-password"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.SetBasicAuth("admin", "secret-password")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 詳細表示で読んだ本文も呼び出し側で読める
+		var body bytes.Buffer
+		body.ReadFrom(resp.Body)
+		resp.Body.Close()
+		if !strings.Contains(body.String(), "secret-jwt-token") {
+			t.Errorf("%v: response body = %q", level, body.String())
+		}
+		resp, err = client.Get(server.URL + "/missing")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		output := buf.String()
+		for _, secret := range []string{"secret-password", "secret-jwt-token", "secret-session", "YWRtaW46c2VjcmV0LXBhc3N3b3Jk"} {
+			if strings.Contains(output, secret) {
+				t.Errorf("%v: log contains %q:\n%s", level, secret, output)
+			}
+		}
+
+		var requests []map[string]interface{}
+		detailed := 0
+		for _, record := range traceRecords(t, &buf) {
+			switch record["msg"] {
+			case "HTTP":
+				requests = append(requests, record)
+			case "HTTP リクエスト", "HTTP レスポンス":
+				detailed++
+			}
+		}
+		if len(requests) != 2 {
+			t.Fatalf("%v: %d HTTP records, want 2:\n%s", level, len(requests), output)
+		}
+		for i, want := range []struct {
+			status float64
+			level  string
+		}{{200, "INFO"}, {404, "WARN"}} {
+			record := requests[i]
+			if record["status"] != want.status || record["level"] != want.level {
+				t.Errorf("%v: record %d status = %v, level = %v; want %v, %s", level, i, record["status"], record["level"], want.status, want.level)
+			}
+			if duration, _ := record["duration"].(float64); duration < float64(time.Millisecond) {
+				t.Errorf("%v: record %d duration = %v, want at least 1ms", level, i, record["duration"])
+			}
+		}
+		// ヘッダーと本文は Debug レベルの場合だけ出力する
+		if wantDetailed := map[slog.Level]int{slog.LevelInfo: 0, slog.LevelDebug: 4}[level]; detailed != wantDetailed {
+			t.Errorf("%v: %d request/response records, want %d", level, detailed, wantDetailed)
+		}
+		if level == slog.LevelDebug && (!strings.Contains(output, "REDACTED") || !strings.Contains(output, `\"username\": \"admin\"`)) {
+			t.Errorf("debug log does not show the redacted header and body:\n%s", output)
+		}
+	}
+}
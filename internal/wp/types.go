@@ -1,5 +1,7 @@
 package wp
 
+import "encoding/json"
+
 type PostRequest struct {
 	Title         string `json:"title"`
 	Content       string `json:"content"`
@@ -8,13 +10,72 @@ type PostRequest struct {
 	Categories    []int  `json:"categories"`
 	Tags          []int  `json:"tags"`
 	FeaturedMedia int    `json:"featured_media"`
+	Excerpt       string `json:"excerpt,omitempty"`
+	Author        int    `json:"author,omitempty"`
+	CommentStatus string `json:"comment_status,omitempty"`
+	PingStatus    string `json:"ping_status,omitempty"`
+	Sticky        *bool  `json:"sticky,omitempty"`
+	Format        string `json:"format,omitempty"`
+	// 固定ページなど階層のある投稿タイプ用
+	Parent    int    `json:"parent,omitempty"`
+	MenuOrder int    `json:"menu_order,omitempty"`
+	Template  string `json:"template,omitempty"`
+	// 登録済みのポストメタと ACF のフィールド
+	Meta map[string]interface{} `json:"meta,omitempty"`
+	ACF  map[string]interface{} `json:"acf,omitempty"`
+	// All in One SEO の REST フィールド
+	AIOSEO map[string]interface{} `json:"aioseo_meta_data,omitempty"`
 }
 
 type PostResponse struct {
-	ID      int    `json:"id"`
-	Link    string `json:"link"`
-	Status  string `json:"status"`
-	Message string `json:"message,omitempty"`
+	ID            int           `json:"id"`
+	Link          string        `json:"link"`
+	Status        string        `json:"status"`
+	Message       string        `json:"message,omitempty"`
+	Type          string        `json:"type,omitempty"`
+	Slug          string        `json:"slug,omitempty"`
+	Title         RenderedField `json:"title"`
+	Content       RenderedField `json:"content"`
+	Excerpt       RenderedField `json:"excerpt"`
+	Author        int           `json:"author,omitempty"`
+	CommentStatus string        `json:"comment_status,omitempty"`
+	PingStatus    string        `json:"ping_status,omitempty"`
+	Sticky        bool          `json:"sticky,omitempty"`
+	Format        string        `json:"format,omitempty"`
+	Categories    []int         `json:"categories,omitempty"`
+	Tags          []int         `json:"tags,omitempty"`
+	FeaturedMedia int           `json:"featured_media,omitempty"`
+	Parent        int           `json:"parent,omitempty"`
+	MenuOrder     int           `json:"menu_order,omitempty"`
+	Template      string        `json:"template,omitempty"`
+	ModifiedGMT   string        `json:"modified_gmt,omitempty"`
+	Meta          FieldMap      `json:"meta,omitempty"`
+	ACF           FieldMap      `json:"acf,omitempty"`
+	AIOSEO        FieldMap      `json:"aioseo_meta_data,omitempty"`
+}
+
+// FieldMap は meta や acf のようなキーと値の組です。
+// WordPress は値が空のとき {} ではなく [] を返すため、その場合は空のマップとして扱います。
+type FieldMap map[string]interface{}
+
+func (m *FieldMap) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		*m = FieldMap{}
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*m = fields
+	return nil
+}
+
+// RenderedField は title や content のように raw と rendered を持つフィールドです。
+// raw は context=edit で取得した場合のみ含まれます。
+type RenderedField struct {
+	Raw      string `json:"raw,omitempty"`
+	Rendered string `json:"rendered,omitempty"`
 }
 
 type ArticleMetadata struct {
@@ -23,7 +84,82 @@ type ArticleMetadata struct {
 	Permalink string   `json:"Permalink"`
 	Tag       []string `json:"Tag"`
 	Category  []string `json:"Category"`
-	PostID    int      `json:"post_id,omitempty"`
+	// Status は投稿時のステータス（publish、draft、pending、private）です。省略時は publish です。
+	// delete で投稿をゴミ箱に移した記事は trash になります。
+	Status string `json:"Status,omitempty"`
+	// 以下は指定した場合のみ送信し、省略時は WordPress 側の値を変更しません
+	Excerpt string `json:"Excerpt,omitempty"`
+	// Author は投稿者のユーザー名です
+	Author string `json:"Author,omitempty"`
+	// CommentStatus と PingStatus は open または closed です
+	CommentStatus string `json:"CommentStatus,omitempty"`
+	PingStatus    string `json:"PingStatus,omitempty"`
+	Sticky        *bool  `json:"Sticky,omitempty"`
+	// Format は投稿フォーマット（standard、aside、gallery など）です
+	Format string `json:"Format,omitempty"`
+	// Type は投稿タイプです。post（省略時）、page、またはカスタム投稿タイプのスラッグかREST baseを指定します
+	Type string `json:"Type,omitempty"`
+	// Parent は親ページのスラッグまたはIDです（階層のある投稿タイプのみ）
+	Parent    string `json:"Parent,omitempty"`
+	MenuOrder int    `json:"MenuOrder,omitempty"`
+	Template  string `json:"Template,omitempty"`
+	// Meta は register_post_meta で REST に公開されたポストメタ、ACF は ACF のフィールドです
+	Meta   map[string]interface{} `json:"Meta,omitempty"`
+	ACF    map[string]interface{} `json:"ACF,omitempty"`
+	SEO    *SEOMetadata           `json:"SEO,omitempty"`
+	PostID int                    `json:"post_id,omitempty"`
+	// Sync は最後に投稿・更新したときの WordPress 上の状態で、競合の検出に使います
+	Sync *SyncState `json:"sync,omitempty"`
+	// Profiles は既定以外のプロファイルでの投稿IDと同期状態です。
+	// WithProfile でプロファイルの値が PostID と Sync に入れ替わります。
+	Profiles map[string]ProfileState `json:"profiles,omitempty"`
+
+	// profile は PostID と Sync に入れ替えているプロファイルです。空の場合は既定のプロファイルです。
+	profile string
+	// defaultState は profile を使っている間、既定のプロファイルの PostID と Sync を退避します
+	defaultState ProfileState
+}
+
+// ProfileState はプロファイルごとの投稿IDと同期状態です
+type ProfileState struct {
+	PostID int        `json:"post_id,omitempty"`
+	Sync   *SyncState `json:"sync,omitempty"`
+}
+
+// SyncState は最後に投稿・更新したときの投稿の更新日時と本文のハッシュです。
+// LocalHash はそのときのローカルの記事ファイルのハッシュで、ローカルの変更の検出に使います。
+type SyncState struct {
+	ModifiedGMT string `json:"modified_gmt"`
+	ContentHash string `json:"content_hash"`
+	LocalHash   string `json:"local_hash,omitempty"`
+}
+
+// SEOMetadata は SEO プラグインに設定する項目です
+type SEOMetadata struct {
+	Title        string `json:"Title,omitempty"`
+	Description  string `json:"Description,omitempty"`
+	FocusKeyword string `json:"FocusKeyword,omitempty"`
+	Canonical    string `json:"Canonical,omitempty"`
+	NoIndex      bool   `json:"NoIndex,omitempty"`
+	// OGImage は internal/images 内の画像ファイル名、または画像のURLです
+	OGImage string `json:"OGImage,omitempty"`
+}
+
+// User は /wp/v2/users で取得できるユーザーです。Username は context=edit の場合のみ含まれます。
+type User struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Username string `json:"username,omitempty"`
+}
+
+// PostType は /wp/v2/types で取得できる投稿タイプの情報です
+type PostType struct {
+	Slug         string   `json:"slug"`
+	Name         string   `json:"name"`
+	RestBase     string   `json:"rest_base"`
+	Hierarchical bool     `json:"hierarchical"`
+	Taxonomies   []string `json:"taxonomies"`
 }
 
 type Category struct {
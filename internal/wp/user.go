@@ -0,0 +1,101 @@
+package wp
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// FindUserID はユーザー名（ログイン名）またはスラッグからユーザーIDを検索します。
+// ユーザー名での検索には一覧の取得権限（list_users）が必要です。
+func FindUserID(client API, username string) (int, error) {
+	cache := client.SharedCache()
+	if id, ok := cache.lookupUser(username); ok {
+		return id, nil
+	}
+
+	users, err := client.SearchUsers(username)
+	if err != nil {
+		return 0, fmt.Errorf("ユーザー検索エラー: %w", err)
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Username, username) || strings.EqualFold(u.Slug, username) {
+			cache.addUser(u)
+			return u.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("ユーザーが見つかりません: %s", username)
+}
+
+// GetUser はユーザーIDからユーザーを取得します
+func GetUser(client API, id int) (*User, error) {
+	user, err := client.GetUser(id)
+	if err != nil {
+		return nil, fmt.Errorf("ユーザー取得エラー: %w", err)
+	}
+	return user, nil
+}
+
+// GetCurrentUser は認証したユーザーを取得します。認証情報の確認に使います。
+func GetCurrentUser(client API) (*User, error) {
+	user, err := client.CurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("ユーザー取得エラー: %w", err)
+	}
+	return user, nil
+}
+
+// SearchUsers はユーザー名・スラッグ・表示名でユーザーを検索します
+func (c *Client) SearchUsers(search string) ([]User, error) {
+	var users []User
+	path := "/wp-json/wp/v2/users?context=edit&search=" + url.QueryEscape(search)
+	if err := getJSON(c, path, &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetUser はユーザーIDからユーザーを取得します
+func (c *Client) GetUser(id int) (*User, error) {
+	var user User
+	if err := getJSON(c, fmt.Sprintf("/wp-json/wp/v2/users/%d?context=edit", id), &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CurrentUser は認証したユーザーを取得します
+func (c *Client) CurrentUser() (*User, error) {
+	var user User
+	if err := getJSON(c, "/wp-json/wp/v2/users/me?context=edit", &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// lookupUser は記録済みのユーザーIDを返します
+func (c *Cache) lookupUser(username string) (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, ok := c.users[strings.ToLower(username)]
+	return id, ok
+}
+
+// addUser はユーザー名とスラッグの両方でユーザーIDを記録します
+func (c *Cache) addUser(user User) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.users == nil {
+		c.users = make(map[string]int)
+	}
+	if user.Username != "" {
+		c.users[strings.ToLower(user.Username)] = user.ID
+	}
+	c.users[strings.ToLower(user.Slug)] = user.ID
+}
@@ -0,0 +1,127 @@
+package wp
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Workspace は記事と画像の置き場所です
+type Workspace struct {
+	// Articles は記事ファイルのファイルシステムです。記事の書き込みには WriteFileFS が必要です。
+	Articles fs.FS
+	// Images は画像ファイルのファイルシステムです
+	Images fs.FS
+	// ImagePrefix は本文で画像を参照するときのパスの接頭辞です（例: ![](internal/images/a.png) の internal/images）
+	ImagePrefix string
+	// Profile は記事の投稿IDと同期状態、マニフェストを読み書きするプロファイルです（Config.StateKey の値）。
+	// 空の場合は既定のプロファイルとして、記事の post_id と sync を使います。
+	Profile string
+	// DefaultStatus は記事に Status がない場合の公開状態です（プロファイルの default_status）。空の場合は publish です。
+	DefaultStatus string
+}
+
+// DefaultWorkspace は ReadArticleFromMd などのパッケージ関数が使う置き場所です
+var DefaultWorkspace = NewWorkspace("internal/articles", "internal/images")
+
+// NewWorkspace はディレクトリを置き場所にした Workspace を作成します。
+// 本文の画像は imagesDir のパスで参照されているものとして扱います。
+func NewWorkspace(articlesDir, imagesDir string) *Workspace {
+	return &Workspace{
+		Articles:    DirFS(articlesDir),
+		Images:      DirFS(imagesDir),
+		ImagePrefix: filepath.ToSlash(filepath.Clean(imagesDir)),
+	}
+}
+
+// ReadArticle は記事を読み込み、PostID と Sync を置き場所のプロファイルのものにして返します
+func (ws *Workspace) ReadArticle(name string) (ArticleMetadata, string, error) {
+	metadata, body, err := ReadArticleFS(ws.Articles, name)
+	if err != nil {
+		return ArticleMetadata{}, "", err
+	}
+	return metadata.WithProfile(ws.Profile), body, nil
+}
+
+// WriteFileFS はファイルの書き込みに対応したファイルシステムです
+type WriteFileFS interface {
+	fs.FS
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+}
+
+// DirFS はディレクトリ以下を読み書きするファイルシステムです。読み取りは os.DirFS と同じです。
+type DirFS string
+
+func (d DirFS) Open(name string) (fs.File, error) {
+	return os.DirFS(string(d)).Open(name)
+}
+
+func (d DirFS) ReadFile(name string) ([]byte, error) {
+	return fs.ReadFile(os.DirFS(string(d)), name)
+}
+
+// WriteFile は name にファイルを書き込みます。ディレクトリがない場合は作成します。
+func (d DirFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	fullPath := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(fullPath, data, perm)
+}
+
+// CreateFile は name にファイルを作成します。ファイルがすでにある場合は上書きせず、fs.ErrExist のエラーを返します。
+func (d DirFS) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	fullPath := filepath.Join(string(d), filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(fullPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeFile は fsys が WriteFileFS の場合にファイルを書き込みます
+func writeFile(fsys fs.FS, name string, data []byte) error {
+	w, ok := fsys.(WriteFileFS)
+	if !ok {
+		return fmt.Errorf("書き込みに対応していないファイルシステムです: %s", name)
+	}
+	return w.WriteFile(name, data, 0644)
+}
+
+// articlePath は記事名（例: posts_001-050/1）をファイルシステム上のパスにします
+func articlePath(name string) (string, error) {
+	p := path.Clean(name) + ".md"
+	if !fs.ValidPath(p) {
+		return "", fmt.Errorf("記事名が不正です: %s", name)
+	}
+	return p, nil
+}
+
+// ArticleExists は記事ファイルがあるかを返します
+func ArticleExists(fsys fs.FS, name string) (bool, error) {
+	p, err := articlePath(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = fs.Stat(fsys, p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
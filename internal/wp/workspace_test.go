@@ -0,0 +1,65 @@
+package wp_test
+
+import (
+	"testing"
+
+	"wp/internal/wp"
+	"wp/internal/wp/wptest"
+)
+
+const profileArticle = `{
+  "Title": "記事",
+  "post_id": 10,
+  "profiles": {
+    "staging": {
+      "post_id": 20
+    }
+  }
+}
+---
+本文
+`
+
+// 同じ記事を別のプロファイルの置き場所から同時に読み書きできる
+func TestWorkspaceProfiles(t *testing.T) {
+	fsys := wptest.NewMemFS(map[string]string{"a.md": profileArticle})
+	production := &wp.Workspace{Articles: fsys}
+	staging := &wp.Workspace{Articles: fsys, Profile: "staging"}
+
+	metadata, _, err := staging.ReadArticle("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.PostID != 20 || metadata.StateFor("").PostID != 10 {
+		t.Errorf("staging PostID = %d, default = %d; want 20, 10", metadata.PostID, metadata.StateFor("").PostID)
+	}
+	metadata.PostID = 21
+	if err := wp.UpdateMetadataFS(fsys, "a", metadata); err != nil {
+		t.Fatal(err)
+	}
+	if err := staging.RecordPost("a", 21, "post"); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, _, err = production.ReadArticle("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.PostID != 10 || metadata.StateFor("staging").PostID != 21 {
+		t.Errorf("production PostID = %d, staging = %d; want 10, 21", metadata.PostID, metadata.StateFor("staging").PostID)
+	}
+	manifest, err := production.LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Posts) != 0 {
+		t.Errorf("default manifest = %v, want empty", manifest.Posts)
+	}
+	manifest, err = staging.LoadManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Posts["a"].PostID != 21 {
+		t.Errorf("staging manifest = %v, want a: 21", manifest.Posts)
+	}
+}
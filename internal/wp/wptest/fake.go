@@ -0,0 +1,962 @@
+// Package wptest は WordPress の REST API をメモリ上で再現し、投稿処理をオフラインで確認するためのパッケージです。
+package wptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"wp/internal/wp"
+)
+
+// timeFormat は REST API の日時（date_gmt、modified_gmt など）の形式です
+const timeFormat = "2006-01-02T15:04:05"
+
+// defaultCategory はカテゴリーを指定しない投稿に設定するカテゴリー（未分類）のIDです
+const defaultCategory = 1
+
+// imageTypes はアップロードできる画像の拡張子と MIME タイプです
+var imageTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".gif":  "image/gif",
+	".webp": "image/webp",
+	".avif": "image/avif",
+	".ico":  "image/x-icon",
+	".bmp":  "image/bmp",
+}
+
+// postStatuses は投稿・更新で指定できる公開状態です
+var postStatuses = []string{"publish", "future", "draft", "pending", "private"}
+
+// Fake はメモリ上の WordPress です。wp.API を実装し、ServeHTTP で /wp-json/wp/v2 の REST API としても応答します。
+// 投稿・メディア・リビジョンのIDは WordPress と同じく共通の連番です。
+type Fake struct {
+	// BaseURL は投稿の link とメディアの source_url に使うサイトの URL です。NewServer ではサーバーの URL になります。
+	BaseURL string
+	// Username と Password を設定すると REST API に Basic 認証を要求します。
+	// アプリケーションパスワードと同じく、パスワードの空白は無視します。
+	Username string
+	Password string
+	// Cache は wp.API として使うときに、Client と同じくアップロードしたメディアなどを記録するキャッシュです
+	Cache *wp.Cache
+
+	mu         sync.Mutex
+	now        time.Time
+	nextID     int
+	nextTermID int
+	postTypes  map[string]wp.PostType
+	posts      map[int]*wp.PostResponse
+	revisions  map[int][]wp.Revision
+	fields     map[string]map[string]wp.SchemaProperty
+	meta       map[string]map[string]wp.SchemaProperty
+	terms      map[string][]term
+	media      map[int]*mediaItem
+	users      []wp.User
+}
+
+// term はカテゴリーまたはタグです
+type term struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	Taxonomy string `json:"taxonomy"`
+	Parent   int    `json:"parent,omitempty"`
+}
+
+type mediaItem struct {
+	wp.MediaResponse
+	name     string
+	mimeType string
+	data     []byte
+}
+
+// NewFake は投稿（post）と固定ページ（page）、カテゴリー「未分類」、管理者ユーザー（ID 1、admin）だけがある WordPress を作成します
+func NewFake() *Fake {
+	f := &Fake{
+		BaseURL:    "http://wordpress.test",
+		now:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		nextID:     1,
+		nextTermID: 2,
+		postTypes: map[string]wp.PostType{
+			"post": {Slug: "post", Name: "投稿", RestBase: "posts", Taxonomies: []string{"category", "post_tag"}},
+			"page": {Slug: "page", Name: "固定ページ", RestBase: "pages", Hierarchical: true},
+		},
+		posts:     make(map[int]*wp.PostResponse),
+		revisions: make(map[int][]wp.Revision),
+		fields:    make(map[string]map[string]wp.SchemaProperty),
+		meta:      make(map[string]map[string]wp.SchemaProperty),
+		terms: map[string][]term{
+			"category": {{ID: defaultCategory, Name: "未分類", Slug: "uncategorized", Taxonomy: "category"}},
+		},
+		media: make(map[int]*mediaItem),
+		users: []wp.User{{ID: 1, Name: "admin", Slug: "admin", Username: "admin"}},
+		Cache: wp.NewCache(),
+	}
+	return f
+}
+
+var _ wp.API = (*Fake)(nil)
+
+// AddPostType はカスタム投稿タイプを登録します。RestBase が空の場合はスラッグを使います。
+func (f *Fake) AddPostType(t wp.PostType) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if t.RestBase == "" {
+		t.RestBase = t.Slug
+	}
+	f.postTypes[t.Slug] = t
+}
+
+// RegisterMeta は register_post_meta と同じく、ポストメタを REST API に公開します。
+// 登録していないキーは投稿・更新で送信しても無視されます。
+func (f *Fake) RegisterMeta(restBase, key string, prop wp.SchemaProperty) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.meta[restBase] == nil {
+		f.meta[restBase] = make(map[string]wp.SchemaProperty)
+	}
+	f.meta[restBase][key] = prop
+}
+
+// RegisterField は acf や aioseo_meta_data のようにプラグインが追加するフィールドをスキーマに登録します
+func (f *Fake) RegisterField(restBase, field string, prop wp.SchemaProperty) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fields[restBase] == nil {
+		f.fields[restBase] = make(map[string]wp.SchemaProperty)
+	}
+	f.fields[restBase][field] = prop
+}
+
+// AddUser はユーザーを追加します。ID が 0 の場合は採番します。
+func (f *Fake) AddUser(user wp.User) wp.User {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if user.ID == 0 {
+		for _, u := range f.users {
+			user.ID = max(user.ID, u.ID)
+		}
+		user.ID++
+	}
+	f.users = append(f.users, user)
+	return user
+}
+
+// Post は投稿タイプを問わず、IDの投稿を返します
+func (f *Fake) Post(id int) (*wp.PostResponse, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post, ok := f.posts[id]
+	if !ok {
+		return nil, false
+	}
+	return clonePost(post), true
+}
+
+// Posts は REST base の投稿を、ゴミ箱の投稿も含めてID順に返します
+func (f *Fake) Posts(restBase string) []wp.PostResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, ok := f.typeOf(restBase)
+	if !ok {
+		return nil
+	}
+	var posts []wp.PostResponse
+	for _, id := range f.postIDs() {
+		if f.posts[id].Type == t.Slug {
+			posts = append(posts, *clonePost(f.posts[id]))
+		}
+	}
+	return posts
+}
+
+// Media はアップロードされたメディアをID順に返します
+func (f *Fake) Media() []wp.MediaResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	ids := make([]int, 0, len(f.media))
+	for id := range f.media {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	media := make([]wp.MediaResponse, 0, len(ids))
+	for _, id := range ids {
+		media = append(media, f.media[id].MediaResponse)
+	}
+	return media
+}
+
+// wp.API の実装。エラーは REST API と同じ *wp.APIError です。
+
+func (f *Fake) PostTypes() (map[string]wp.PostType, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	types := make(map[string]wp.PostType, len(f.postTypes))
+	for slug, t := range f.postTypes {
+		types[slug] = t
+	}
+	return types, nil
+}
+
+func (f *Fake) PostSchema(restBase string) (*wp.PostSchema, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.typeOf(restBase); !ok {
+		return nil, errNoRoute()
+	}
+	return &wp.PostSchema{Properties: f.schemaProperties(restBase)}, nil
+}
+
+func (f *Fake) ListPosts(restBase string, query wp.PostQuery) ([]wp.PostResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.typeOf(restBase); !ok {
+		return nil, errNoRoute()
+	}
+	found, apiErr := f.listPosts(restBase, query)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	posts := make([]wp.PostResponse, len(found))
+	for i, post := range found {
+		posts[i] = *clonePost(post)
+	}
+	return posts, nil
+}
+
+func (f *Fake) GetPostOfType(restBase string, postID int) (*wp.PostResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post, apiErr := f.getPost(restBase, postID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return clonePost(post), nil
+}
+
+func (f *Fake) CreatePostOfType(restBase string, post wp.PostRequest) (*wp.PostResponse, error) {
+	body, err := json.Marshal(post)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	created, apiErr := f.savePost(restBase, 0, body)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return created, nil
+}
+
+func (f *Fake) UpdatePostOfType(restBase string, postID int, post wp.PostRequest) (*wp.PostResponse, error) {
+	return f.UpdatePostFields(restBase, postID, toFields(post))
+}
+
+func (f *Fake) UpdatePostFields(restBase string, postID int, fields map[string]interface{}) (*wp.PostResponse, error) {
+	body, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	updated, apiErr := f.savePost(restBase, postID, body)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return updated, nil
+}
+
+func (f *Fake) DeletePost(restBase string, postID int, force bool) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, apiErr := f.deletePost(restBase, postID, force); apiErr != nil {
+		return apiErr
+	}
+	return nil
+}
+
+func (f *Fake) Revisions(restBase string, postID int) ([]wp.Revision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, apiErr := f.getPost(restBase, postID); apiErr != nil {
+		return nil, apiErr
+	}
+	return append([]wp.Revision(nil), f.revisions[postID]...), nil
+}
+
+func (f *Fake) Revision(restBase string, postID, revisionID int) (*wp.Revision, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	revision, apiErr := f.revision(restBase, postID, revisionID)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return revision, nil
+}
+
+func (f *Fake) Categories() ([]wp.Category, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var categories []wp.Category
+	for _, t := range f.sortedTerms("category") {
+		categories = append(categories, wp.Category{ID: t.ID, Name: t.Name})
+	}
+	return categories, nil
+}
+
+func (f *Fake) DefaultCategory() (int, error) {
+	return defaultCategory, nil
+}
+
+func (f *Fake) CreateCategory(name string) (*wp.Category, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, apiErr := f.createTerm("category", name, "")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return &wp.Category{ID: t.ID, Name: t.Name}, nil
+}
+
+func (f *Fake) Tags() ([]wp.Tag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var tags []wp.Tag
+	for _, t := range f.sortedTerms("post_tag") {
+		tags = append(tags, wp.Tag{ID: t.ID, Name: t.Name})
+	}
+	return tags, nil
+}
+
+func (f *Fake) CreateTag(name string) (*wp.Tag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	t, apiErr := f.createTerm("post_tag", name, "")
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return &wp.Tag{ID: t.ID, Name: t.Name}, nil
+}
+
+func (f *Fake) UploadMedia(name string, data []byte) (*wp.MediaResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, apiErr := f.uploadMedia(name, data)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	media := item.MediaResponse
+	return &media, nil
+}
+
+func (f *Fake) GetMedia(id int) (*wp.MediaResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.media[id]
+	if !ok {
+		return nil, apiError(http.StatusNotFound, "rest_post_invalid_id", "Invalid post ID.")
+	}
+	media := item.MediaResponse
+	return &media, nil
+}
+
+func (f *Fake) SearchMedia(search string) ([]wp.MediaResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var media []wp.MediaResponse
+	for _, item := range f.searchMedia(search) {
+		media = append(media, item.MediaResponse)
+	}
+	return media, nil
+}
+
+func (f *Fake) DownloadMedia(media wp.MediaResponse) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, item := range f.media {
+		if item.URL == media.URL {
+			return append([]byte(nil), item.data...), nil
+		}
+	}
+	return nil, fmt.Errorf("画像取得エラー: %d", http.StatusNotFound)
+}
+
+func (f *Fake) SearchUsers(search string) ([]wp.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.searchUsers(search), nil
+}
+
+func (f *Fake) GetUser(id int) (*wp.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	user, apiErr := f.getUser(id)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	return user, nil
+}
+
+func (f *Fake) CurrentUser() (*wp.User, error) {
+	return f.GetUser(1)
+}
+
+func (f *Fake) SharedCache() *wp.Cache {
+	return f.Cache
+}
+
+// 以下は呼び出し側で mu を保持していること
+
+// tick は時計を1秒進めて現在の日時を返します。modified_gmt で競合を検出できるよう、変更ごとに異なる日時にします。
+func (f *Fake) tick() string {
+	f.now = f.now.Add(time.Second)
+	return f.now.Format(timeFormat)
+}
+
+func (f *Fake) newID() int {
+	id := f.nextID
+	f.nextID++
+	return id
+}
+
+// typeOf は REST base の投稿タイプを返します
+func (f *Fake) typeOf(restBase string) (wp.PostType, bool) {
+	for _, t := range f.postTypes {
+		if t.RestBase == restBase {
+			return t, true
+		}
+	}
+	return wp.PostType{}, false
+}
+
+func (f *Fake) postIDs() []int {
+	ids := make([]int, 0, len(f.posts))
+	for id := range f.posts {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (f *Fake) getPost(restBase string, id int) (*wp.PostResponse, *wp.APIError) {
+	t, ok := f.typeOf(restBase)
+	if !ok {
+		return nil, errNoRoute()
+	}
+	post, ok := f.posts[id]
+	if !ok || post.Type != t.Slug {
+		return nil, apiError(http.StatusNotFound, "rest_post_invalid_id", "Invalid post ID.")
+	}
+	return post, nil
+}
+
+// listPosts は条件に合う投稿を新しい順に返します。Status の "any" はゴミ箱以外のすべてのステータスです。
+func (f *Fake) listPosts(restBase string, query wp.PostQuery) ([]*wp.PostResponse, *wp.APIError) {
+	t, _ := f.typeOf(restBase)
+	statuses := query.Status
+	switch {
+	case len(statuses) == 0:
+		statuses = []string{"publish"}
+	case len(statuses) == 1 && statuses[0] == "any":
+		statuses = postStatuses
+	}
+	for _, s := range statuses {
+		if !contains(postStatuses, s) && s != "trash" {
+			return nil, apiError(http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): status")
+		}
+	}
+	var slugs []string
+	if query.Slug != "" {
+		for _, s := range strings.Split(query.Slug, ",") {
+			slugs = append(slugs, sanitizeSlug(s))
+		}
+	}
+
+	var posts []*wp.PostResponse
+	ids := f.postIDs()
+	sort.Sort(sort.Reverse(sort.IntSlice(ids)))
+	for _, id := range ids {
+		post := f.posts[id]
+		if post.Type != t.Slug || !contains(statuses, post.Status) || (slugs != nil && !contains(slugs, post.Slug)) {
+			continue
+		}
+		if query.Include != nil && !containsID(query.Include, id) {
+			continue
+		}
+		posts = append(posts, post)
+	}
+	return posts, nil
+}
+
+// revision は投稿のリビジョンを1件返します
+func (f *Fake) revision(restBase string, postID, revisionID int) (*wp.Revision, *wp.APIError) {
+	if _, apiErr := f.getPost(restBase, postID); apiErr != nil {
+		return nil, apiErr
+	}
+	for _, revision := range f.revisions[postID] {
+		if revision.ID == revisionID {
+			return &revision, nil
+		}
+	}
+	return nil, apiError(http.StatusNotFound, "rest_post_invalid_id", "Invalid revision ID.")
+}
+
+// savePost は JSON のリクエストボディで投稿を作成（id が 0）または更新します。
+// 更新では送信されたフィールドだけを変更します。
+func (f *Fake) savePost(restBase string, id int, body []byte) (*wp.PostResponse, *wp.APIError) {
+	t, ok := f.typeOf(restBase)
+	if !ok {
+		return nil, errNoRoute()
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, apiError(http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+	}
+
+	var post *wp.PostResponse
+	if id == 0 {
+		post = &wp.PostResponse{
+			Type:          t.Slug,
+			Status:        "draft",
+			Author:        1,
+			CommentStatus: "open",
+			PingStatus:    "open",
+			Format:        "standard",
+		}
+	} else {
+		existing, apiErr := f.getPost(restBase, id)
+		if apiErr != nil {
+			return nil, apiErr
+		}
+		post = clonePost(existing)
+	}
+	before := *clonePost(post)
+
+	if apiErr := f.applyFields(t, post, fields); apiErr != nil {
+		return nil, apiErr
+	}
+
+	if id == 0 {
+		post.ID = f.newID()
+		if t.HasTaxonomy("category") && len(post.Categories) == 0 {
+			post.Categories = []int{defaultCategory}
+		}
+	}
+	if post.Slug == "" && post.Status == "publish" {
+		post.Slug = sanitizeSlug(post.Title.Raw)
+		if post.Slug == "" {
+			post.Slug = strconv.Itoa(post.ID)
+		}
+	}
+	if post.Slug != before.Slug && post.Slug != "" {
+		post.Slug = f.uniqueSlug(t.Slug, post.ID, post.Slug)
+	}
+	post.Link = f.link(t, post)
+	post.ModifiedGMT = f.tick()
+
+	// タイトル・本文・抜粋が変わった場合はリビジョンを残す
+	if id == 0 || post.Title.Raw != before.Title.Raw || post.Content.Raw != before.Content.Raw || post.Excerpt.Raw != before.Excerpt.Raw {
+		f.addRevision(post)
+	}
+
+	f.posts[post.ID] = post
+	return clonePost(post), nil
+}
+
+// applyFields はリクエストのフィールドを投稿に反映します
+func (f *Fake) applyFields(t wp.PostType, post *wp.PostResponse, fields map[string]json.RawMessage) *wp.APIError {
+	invalid := func(name string) *wp.APIError {
+		return apiError(http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): "+name)
+	}
+
+	for name, value := range fields {
+		if string(value) == "null" {
+			continue
+		}
+		var err error
+		switch name {
+		case "title":
+			err = decodeText(value, &post.Title)
+		case "content":
+			err = decodeText(value, &post.Content)
+		case "excerpt":
+			err = decodeText(value, &post.Excerpt)
+		case "status":
+			var status string
+			if err = json.Unmarshal(value, &status); err == nil && !contains(postStatuses, status) {
+				return invalid(name)
+			}
+			post.Status = status
+		case "slug":
+			var slug string
+			err = json.Unmarshal(value, &slug)
+			post.Slug = sanitizeSlug(slug)
+		case "categories", "tags":
+			taxonomy := map[string]string{"categories": "category", "tags": "post_tag"}[name]
+			if !t.HasTaxonomy(taxonomy) {
+				continue
+			}
+			var ids []int
+			if err = json.Unmarshal(value, &ids); err != nil {
+				break
+			}
+			for _, termID := range ids {
+				if !f.termExists(taxonomy, termID) {
+					return apiError(http.StatusBadRequest, "rest_invalid_term_id", "Invalid term ID.")
+				}
+			}
+			if name == "categories" {
+				post.Categories = ids
+			} else {
+				post.Tags = ids
+			}
+		case "featured_media":
+			var mediaID int
+			if err = json.Unmarshal(value, &mediaID); err == nil && mediaID != 0 && f.media[mediaID] == nil {
+				return apiError(http.StatusBadRequest, "rest_invalid_featured_media", "Invalid featured media ID.")
+			}
+			post.FeaturedMedia = mediaID
+		case "author":
+			var author int
+			if err = json.Unmarshal(value, &author); err != nil {
+				break
+			}
+			if _, apiErr := f.getUser(author); apiErr != nil {
+				return apiError(http.StatusBadRequest, "rest_invalid_author", "Invalid author ID.")
+			}
+			post.Author = author
+		case "comment_status":
+			err = json.Unmarshal(value, &post.CommentStatus)
+		case "ping_status":
+			err = json.Unmarshal(value, &post.PingStatus)
+		case "sticky":
+			err = json.Unmarshal(value, &post.Sticky)
+		case "format":
+			err = json.Unmarshal(value, &post.Format)
+		case "parent":
+			var parent int
+			if err = json.Unmarshal(value, &parent); err == nil && parent != 0 {
+				if !t.Hierarchical || f.posts[parent] == nil || parent == post.ID {
+					return apiError(http.StatusBadRequest, "rest_post_invalid_id", "Invalid post parent ID.")
+				}
+			}
+			post.Parent = parent
+		case "menu_order":
+			err = json.Unmarshal(value, &post.MenuOrder)
+		case "template":
+			err = json.Unmarshal(value, &post.Template)
+		case "meta":
+			var meta map[string]interface{}
+			if err = json.Unmarshal(value, &meta); err != nil {
+				break
+			}
+			// 登録されていないメタは WordPress と同じく無視する
+			for key, v := range meta {
+				if _, ok := f.meta[t.RestBase][key]; !ok {
+					continue
+				}
+				if post.Meta == nil {
+					post.Meta = wp.FieldMap{}
+				}
+				post.Meta[key] = v
+			}
+		case "acf", "aioseo_meta_data":
+			if _, ok := f.fields[t.RestBase][name]; !ok {
+				continue
+			}
+			var values map[string]interface{}
+			if err = json.Unmarshal(value, &values); err != nil {
+				break
+			}
+			target := &post.ACF
+			if name == "aioseo_meta_data" {
+				target = &post.AIOSEO
+			}
+			if *target == nil {
+				*target = wp.FieldMap{}
+			}
+			for key, v := range values {
+				(*target)[key] = v
+			}
+		}
+		if err != nil {
+			return invalid(name)
+		}
+	}
+	return nil
+}
+
+func (f *Fake) addRevision(post *wp.PostResponse) {
+	revision := wp.Revision{
+		ID:          f.newID(),
+		Author:      post.Author,
+		Date:        post.ModifiedGMT,
+		DateGMT:     post.ModifiedGMT,
+		ModifiedGMT: post.ModifiedGMT,
+		Parent:      post.ID,
+		Title:       post.Title,
+		Content:     post.Content,
+		Excerpt:     post.Excerpt,
+	}
+	// リビジョンは新しい順に返す
+	f.revisions[post.ID] = append([]wp.Revision{revision}, f.revisions[post.ID]...)
+}
+
+// uniqueSlug は同じ投稿タイプの他の投稿と重ならないよう、WordPress と同じく -2、-3 を付けます
+func (f *Fake) uniqueSlug(typeSlug string, id int, slug string) string {
+	taken := func(s string) bool {
+		for _, p := range f.posts {
+			if p.ID != id && p.Type == typeSlug && p.Slug == s {
+				return true
+			}
+		}
+		return false
+	}
+	candidate := slug
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s-%d", slug, n)
+	}
+	return candidate
+}
+
+func (f *Fake) link(t wp.PostType, post *wp.PostResponse) string {
+	if post.Status != "publish" {
+		if t.Slug == "page" {
+			return fmt.Sprintf("%s/?page_id=%d", f.BaseURL, post.ID)
+		}
+		return fmt.Sprintf("%s/?p=%d", f.BaseURL, post.ID)
+	}
+	link := post.Slug + "/"
+	for parent := f.posts[post.Parent]; parent != nil && t.Hierarchical; parent = f.posts[parent.Parent] {
+		link = parent.Slug + "/" + link
+	}
+	if t.Slug != "post" && t.Slug != "page" {
+		link = t.Slug + "/" + link
+	}
+	return f.BaseURL + "/" + link
+}
+
+// deletePost は投稿をゴミ箱に移すか、force の場合は完全に削除します。
+// 完全に削除した場合は {deleted, previous} を返します。
+func (f *Fake) deletePost(restBase string, id int, force bool) (interface{}, *wp.APIError) {
+	post, apiErr := f.getPost(restBase, id)
+	if apiErr != nil {
+		return nil, apiErr
+	}
+	if force {
+		delete(f.posts, id)
+		delete(f.revisions, id)
+		return map[string]interface{}{"deleted": true, "previous": clonePost(post)}, nil
+	}
+	if post.Status == "trash" {
+		return nil, apiError(http.StatusGone, "rest_already_trashed", "The post has already been deleted.")
+	}
+	post.Status = "trash"
+	post.ModifiedGMT = f.tick()
+	return clonePost(post), nil
+}
+
+func (f *Fake) sortedTerms(taxonomy string) []term {
+	terms := append([]term(nil), f.terms[taxonomy]...)
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Name < terms[j].Name })
+	return terms
+}
+
+func (f *Fake) termExists(taxonomy string, id int) bool {
+	for _, t := range f.terms[taxonomy] {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// createTerm はタームを作成します。同じ名前またはスラッグのタームがある場合は
+// WordPress と同じく term_exists のエラーに既存のタームのIDを含めて返します。
+func (f *Fake) createTerm(taxonomy, name, slug string) (*term, *wp.APIError) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, apiError(http.StatusBadRequest, "empty_term_name", "A name is required for this term.")
+	}
+	if slug == "" {
+		slug = sanitizeSlug(name)
+	}
+	for _, t := range f.terms[taxonomy] {
+		if strings.EqualFold(t.Name, name) || t.Slug == slug {
+			apiErr := apiError(http.StatusBadRequest, "term_exists", "A term with the name provided already exists in this taxonomy.")
+			apiErr.TermID = t.ID
+			return nil, apiErr
+		}
+	}
+	t := term{ID: f.nextTermID, Name: name, Slug: slug, Taxonomy: taxonomy}
+	f.nextTermID++
+	f.terms[taxonomy] = append(f.terms[taxonomy], t)
+	return &t, nil
+}
+
+// uploadMedia はメディアを保存します。同じファイル名がある場合は WordPress と同じく -1、-2 を付けます。
+func (f *Fake) uploadMedia(name string, data []byte) (*mediaItem, *wp.APIError) {
+	if len(data) == 0 {
+		return nil, apiError(http.StatusBadRequest, "rest_upload_no_data", "No data supplied.")
+	}
+	// WordPress と同じく拡張子でファイルの種類を判定する
+	ext := path.Ext(name)
+	mimeType, ok := imageTypes[strings.ToLower(ext)]
+	if !ok {
+		return nil, apiError(http.StatusInternalServerError, "rest_upload_sideload_error", "Sorry, you are not allowed to upload this file type.")
+	}
+
+	base := sanitizeSlug(strings.TrimSuffix(path.Base(name), ext))
+	if base == "" {
+		base = "image"
+	}
+	fileName := base + strings.ToLower(ext)
+	for n := 1; f.mediaByName(fileName) != nil; n++ {
+		fileName = fmt.Sprintf("%s-%d%s", base, n, strings.ToLower(ext))
+	}
+
+	item := &mediaItem{name: fileName, mimeType: mimeType, data: append([]byte(nil), data...)}
+	item.ID = f.newID()
+	item.URL = fmt.Sprintf("%s/wp-content/uploads/%s/%s", f.BaseURL, f.now.Format("2006/01"), fileName)
+	f.media[item.ID] = item
+	f.tick()
+	return item, nil
+}
+
+func (f *Fake) mediaByName(name string) *mediaItem {
+	for _, item := range f.media {
+		if item.name == name {
+			return item
+		}
+	}
+	return nil
+}
+
+// searchMedia はファイル名で画像を検索し、ID の順に返します
+func (f *Fake) searchMedia(search string) []*mediaItem {
+	search = strings.ToLower(search)
+	var items []*mediaItem
+	for _, id := range f.mediaIDs() {
+		if item := f.media[id]; strings.Contains(strings.ToLower(item.name), search) {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func (f *Fake) mediaIDs() []int {
+	ids := make([]int, 0, len(f.media))
+	for id := range f.media {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (f *Fake) getUser(id int) (*wp.User, *wp.APIError) {
+	for _, u := range f.users {
+		if u.ID == id {
+			user := u
+			return &user, nil
+		}
+	}
+	return nil, apiError(http.StatusNotFound, "rest_user_invalid_id", "Invalid user ID.")
+}
+
+func (f *Fake) searchUsers(search string) []wp.User {
+	search = strings.ToLower(search)
+	var users []wp.User
+	for _, u := range f.users {
+		if strings.Contains(strings.ToLower(u.Username), search) ||
+			strings.Contains(strings.ToLower(u.Slug), search) ||
+			strings.Contains(strings.ToLower(u.Name), search) {
+			users = append(users, u)
+		}
+	}
+	return users
+}
+
+// apiError は REST API のエラーを作成します
+func apiError(status int, code, message string) *wp.APIError {
+	return &wp.APIError{StatusCode: status, Code: code, Message: message}
+}
+
+func errNoRoute() *wp.APIError {
+	return apiError(http.StatusNotFound, "rest_no_route", "No route was found matching the URL and request method.")
+}
+
+// decodeText は title などの値を文字列または {"raw": ...} として読み取ります
+func decodeText(value json.RawMessage, field *wp.RenderedField) error {
+	var text string
+	if err := json.Unmarshal(value, &text); err != nil {
+		var obj struct {
+			Raw string `json:"raw"`
+		}
+		if err := json.Unmarshal(value, &obj); err != nil {
+			return err
+		}
+		text = obj.Raw
+	}
+	*field = wp.RenderedField{Raw: text, Rendered: text}
+	return nil
+}
+
+// sanitizeSlug は WordPress の sanitize_title と同じく、英数字を小文字にし、空白をハイフンにして、
+// ASCII 以外の文字はパーセントエンコードします
+func sanitizeSlug(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(strings.ToLower(strings.TrimSpace(s))) {
+		switch {
+		case c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '_':
+			b.WriteByte(c)
+		case c == ' ':
+			b.WriteByte('-')
+		case c >= 0x80:
+			fmt.Fprintf(&b, "%%%02x", c)
+		}
+	}
+	return b.String()
+}
+
+// toFields は PostRequest を送信される JSON のフィールドにします
+func toFields(post wp.PostRequest) map[string]interface{} {
+	data, _ := json.Marshal(post)
+	var fields map[string]interface{}
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// clonePost は呼び出し側が変更しても影響しないよう投稿をコピーします
+func clonePost(post *wp.PostResponse) *wp.PostResponse {
+	data, _ := json.Marshal(post)
+	var copied wp.PostResponse
+	json.Unmarshal(data, &copied)
+	return &copied
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
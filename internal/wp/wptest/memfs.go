@@ -0,0 +1,76 @@
+package wptest
+
+import (
+	"io/fs"
+	"sync"
+	"testing/fstest"
+	"time"
+
+	"wp/internal/wp"
+)
+
+// MemFS はメモリ上の読み書きできるファイルシステムです。wp.Workspace の記事・画像の置き場所に使えます。
+type MemFS struct {
+	mu    sync.Mutex
+	files fstest.MapFS
+}
+
+var (
+	_ wp.WriteFileFS  = (*MemFS)(nil)
+	_ wp.CreateFileFS = (*MemFS)(nil)
+)
+
+// NewMemFS はパスと内容の組からファイルシステムを作成します
+func NewMemFS(files map[string]string) *MemFS {
+	m := &MemFS{files: make(fstest.MapFS)}
+	for name, content := range files {
+		m.files[name] = &fstest.MapFile{Data: []byte(content), Mode: 0644}
+	}
+	return m
+}
+
+func (m *MemFS) Open(name string) (fs.File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.Open(name)
+}
+
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.files.ReadFile(name)
+}
+
+// WriteFile は name にファイルを書き込みます。ディレクトリは自動的に作成されます。
+func (m *MemFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "write", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.files[name] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: perm, ModTime: time.Now()}
+	return nil
+}
+
+// CreateFile は name にファイルを作成します。ファイルがすでにある場合は fs.ErrExist のエラーを返します。
+func (m *MemFS) CreateFile(name string, data []byte, perm fs.FileMode) error {
+	if !fs.ValidPath(name) || name == "." {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrInvalid}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.files[name]; ok {
+		return &fs.PathError{Op: "create", Path: name, Err: fs.ErrExist}
+	}
+	m.files[name] = &fstest.MapFile{Data: append([]byte(nil), data...), Mode: perm, ModTime: time.Now()}
+	return nil
+}
+
+// NewWorkspace は記事と画像をメモリ上に置いた wp.Workspace を作成します。本文の画像は images/ で参照します。
+func NewWorkspace(articles, images map[string]string) *wp.Workspace {
+	return &wp.Workspace{
+		Articles:    NewMemFS(articles),
+		Images:      NewMemFS(images),
+		ImagePrefix: "images",
+	}
+}
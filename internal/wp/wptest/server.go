@@ -0,0 +1,453 @@
+package wptest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"wp/internal/wp"
+)
+
+// NewServer は f を REST API として公開するテスト用サーバーを起動します。
+// f.BaseURL はサーバーの URL になります。使い終わったら Close で停止してください。
+//
+//	fake := wptest.NewFake()
+//	server := wptest.NewServer(fake)
+//	defer server.Close()
+//	client := wp.NewClient(server.URL, "admin", "password")
+func NewServer(f *Fake) *httptest.Server {
+	server := httptest.NewServer(f)
+	f.mu.Lock()
+	f.BaseURL = server.URL
+	f.mu.Unlock()
+	return server
+}
+
+// ServeHTTP は /wp-json/wp/v2 のルート（投稿タイプ・投稿・リビジョン・ターム・メディア・ユーザー・設定）と
+// アップロードしたメディアのファイルに応答します
+func (f *Fake) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	method := r.Method
+	if override := r.Header.Get("X-HTTP-Method-Override"); override != "" && method == http.MethodPost {
+		method = override
+	}
+
+	switch {
+	case r.URL.Path == "/wp-json" || r.URL.Path == "/wp-json/":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"name":           "wptest",
+			"url":            f.BaseURL,
+			"namespaces":     []string{"wp/v2"},
+			"authentication": map[string]interface{}{"application-passwords": map[string]interface{}{}},
+		})
+		return
+	case strings.HasPrefix(r.URL.Path, "/wp-content/uploads/") && method == http.MethodGet:
+		f.serveUpload(w, r)
+		return
+	}
+
+	route, ok := strings.CutPrefix(r.URL.Path, "/wp-json/wp/v2/")
+	if !ok {
+		writeError(w, errNoRoute())
+		return
+	}
+	if apiErr := f.authenticate(r); apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+
+	status, body, apiErr := f.route(w, r, method, strings.Split(strings.Trim(route, "/"), "/"))
+	if apiErr != nil {
+		writeError(w, apiErr)
+		return
+	}
+	writeJSON(w, status, body)
+}
+
+// route はパスの要素ごとに処理を振り分けます
+func (f *Fake) route(w http.ResponseWriter, r *http.Request, method string, parts []string) (int, interface{}, *wp.APIError) {
+	query := r.URL.Query()
+
+	switch parts[0] {
+	case "types":
+		if len(parts) == 1 && method == http.MethodGet {
+			return http.StatusOK, f.postTypes, nil
+		}
+	case "settings":
+		if len(parts) == 1 && method == http.MethodGet {
+			return http.StatusOK, map[string]interface{}{"title": "wptest", "url": f.BaseURL, "default_category": defaultCategory}, nil
+		}
+	case "users":
+		return f.routeUsers(w, r, method, parts[1:])
+	case "categories", "tags":
+		return f.routeTerms(w, r, method, map[string]string{"categories": "category", "tags": "post_tag"}[parts[0]], parts[1:])
+	case "media":
+		return f.routeMedia(w, r, method, parts[1:])
+	}
+
+	restBase := parts[0]
+	if _, ok := f.typeOf(restBase); !ok {
+		return 0, nil, errNoRoute()
+	}
+
+	switch {
+	case len(parts) == 1 && method == http.MethodGet:
+		posts, apiErr := f.listPosts(restBase, postQuery(query))
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		page, apiErr := paginate(w, r, posts)
+		return http.StatusOK, page, apiErr
+	case len(parts) == 1 && method == http.MethodPost:
+		body, apiErr := readBody(r)
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		post, apiErr := f.savePost(restBase, 0, body)
+		return http.StatusCreated, post, apiErr
+	case len(parts) == 1 && method == http.MethodOptions:
+		return http.StatusOK, map[string]interface{}{
+			"namespace": "wp/v2",
+			"methods":   []string{"GET", "POST"},
+			"schema":    f.schema(restBase),
+		}, nil
+	}
+	if len(parts) == 1 {
+		return 0, nil, errNoRoute()
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, nil, errNoRoute()
+	}
+
+	if len(parts) == 2 {
+		switch method {
+		case http.MethodGet:
+			post, apiErr := f.getPost(restBase, id)
+			if apiErr != nil {
+				return 0, nil, apiErr
+			}
+			return http.StatusOK, post, nil
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+			body, apiErr := readBody(r)
+			if apiErr != nil {
+				return 0, nil, apiErr
+			}
+			post, apiErr := f.savePost(restBase, id, body)
+			return http.StatusOK, post, apiErr
+		case http.MethodDelete:
+			result, apiErr := f.deletePost(restBase, id, query.Get("force") == "true" || query.Get("force") == "1")
+			return http.StatusOK, result, apiErr
+		}
+		return 0, nil, errNoRoute()
+	}
+
+	if parts[2] != "revisions" || method != http.MethodGet || len(parts) > 4 {
+		return 0, nil, errNoRoute()
+	}
+	if _, apiErr := f.getPost(restBase, id); apiErr != nil {
+		return 0, nil, apiErr
+	}
+	if len(parts) == 3 {
+		page, apiErr := paginate(w, r, f.revisions[id])
+		return http.StatusOK, page, apiErr
+	}
+	revisionID, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return 0, nil, errNoRoute()
+	}
+	revision, apiErr := f.revision(restBase, id, revisionID)
+	if apiErr != nil {
+		return 0, nil, apiErr
+	}
+	return http.StatusOK, revision, nil
+}
+
+// postQuery は投稿一覧のクエリパラメーター（include、slug、status）を wp.PostQuery にします
+func postQuery(query url.Values) wp.PostQuery {
+	q := wp.PostQuery{Slug: query.Get("slug")}
+	if status := query.Get("status"); status != "" {
+		q.Status = strings.Split(status, ",")
+	}
+	if include := query.Get("include"); include != "" {
+		q.Include = []int{}
+		for _, s := range strings.Split(include, ",") {
+			if id, err := strconv.Atoi(s); err == nil {
+				q.Include = append(q.Include, id)
+			}
+		}
+	}
+	return q
+}
+
+func (f *Fake) routeUsers(w http.ResponseWriter, r *http.Request, method string, parts []string) (int, interface{}, *wp.APIError) {
+	if method != http.MethodGet || len(parts) > 1 {
+		return 0, nil, errNoRoute()
+	}
+	if len(parts) == 0 {
+		page, apiErr := paginate(w, r, f.searchUsers(r.URL.Query().Get("search")))
+		return http.StatusOK, page, apiErr
+	}
+	id := 1
+	if parts[0] != "me" {
+		var err error
+		if id, err = strconv.Atoi(parts[0]); err != nil {
+			return 0, nil, errNoRoute()
+		}
+	}
+	user, apiErr := f.getUser(id)
+	return http.StatusOK, user, apiErr
+}
+
+func (f *Fake) routeTerms(w http.ResponseWriter, r *http.Request, method, taxonomy string, parts []string) (int, interface{}, *wp.APIError) {
+	switch {
+	case len(parts) == 0 && method == http.MethodGet:
+		page, apiErr := paginate(w, r, f.sortedTerms(taxonomy))
+		return http.StatusOK, page, apiErr
+	case len(parts) == 0 && method == http.MethodPost:
+		body, apiErr := readBody(r)
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		var req struct {
+			Name string `json:"name"`
+			Slug string `json:"slug"`
+		}
+		if err := json.Unmarshal(body, &req); err != nil {
+			return 0, nil, apiError(http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+		}
+		if req.Name == "" {
+			return 0, nil, apiError(http.StatusBadRequest, "rest_missing_callback_param", "Missing parameter(s): name")
+		}
+		t, apiErr := f.createTerm(taxonomy, req.Name, req.Slug)
+		return http.StatusCreated, t, apiErr
+	case len(parts) == 1 && method == http.MethodGet:
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, nil, errNoRoute()
+		}
+		for _, t := range f.terms[taxonomy] {
+			if t.ID == id {
+				return http.StatusOK, t, nil
+			}
+		}
+		return 0, nil, apiError(http.StatusNotFound, "rest_term_invalid", "Term does not exist.")
+	}
+	return 0, nil, errNoRoute()
+}
+
+func (f *Fake) routeMedia(w http.ResponseWriter, r *http.Request, method string, parts []string) (int, interface{}, *wp.APIError) {
+	switch {
+	case len(parts) == 0 && method == http.MethodPost:
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			return 0, nil, apiError(http.StatusBadRequest, "rest_upload_no_data", "No data supplied.")
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return 0, nil, apiError(http.StatusBadRequest, "rest_upload_no_data", "No data supplied.")
+		}
+		item, apiErr := f.uploadMedia(header.Filename, data)
+		if apiErr != nil {
+			return 0, nil, apiErr
+		}
+		return http.StatusCreated, mediaJSON(item), nil
+	case len(parts) == 0 && method == http.MethodGet:
+		var media []map[string]interface{}
+		for _, item := range f.searchMedia(r.URL.Query().Get("search")) {
+			media = append(media, mediaJSON(item))
+		}
+		page, apiErr := paginate(w, r, media)
+		return http.StatusOK, page, apiErr
+	case len(parts) == 1 && method == http.MethodGet:
+		id, err := strconv.Atoi(parts[0])
+		if err != nil {
+			return 0, nil, errNoRoute()
+		}
+		item, ok := f.media[id]
+		if !ok {
+			return 0, nil, apiError(http.StatusNotFound, "rest_post_invalid_id", "Invalid post ID.")
+		}
+		return http.StatusOK, mediaJSON(item), nil
+	}
+	return 0, nil, errNoRoute()
+}
+
+// serveUpload はアップロードしたメディアのファイルを返します
+func (f *Fake) serveUpload(w http.ResponseWriter, r *http.Request) {
+	for _, item := range f.media {
+		if strings.HasSuffix(item.URL, r.URL.Path) {
+			w.Header().Set("Content-Type", item.mimeType)
+			w.Write(item.data)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// listPosts は公開状態（カンマ区切り、既定は publish）とスラッグで絞り込んだ投稿を新しい順に返します
+// schema は OPTIONS で返す投稿タイプのスキーマです
+func (f *Fake) schema(restBase string) map[string]interface{} {
+	t, _ := f.typeOf(restBase)
+	return map[string]interface{}{
+		"$schema":    "http://json-schema.org/draft-04/schema#",
+		"title":      t.Slug,
+		"type":       "object",
+		"properties": f.schemaProperties(restBase),
+	}
+}
+
+// schemaProperties は投稿タイプのスキーマのプロパティです
+func (f *Fake) schemaProperties(restBase string) map[string]wp.SchemaProperty {
+	t, _ := f.typeOf(restBase)
+	text := wp.SchemaProperty{Type: "object", Properties: map[string]wp.SchemaProperty{
+		"raw":      {Type: "string"},
+		"rendered": {Type: "string", ReadOnly: true},
+	}}
+	properties := map[string]wp.SchemaProperty{
+		"id":             {Type: "integer", ReadOnly: true},
+		"link":           {Type: "string", ReadOnly: true},
+		"modified_gmt":   {Type: "string", ReadOnly: true},
+		"title":          text,
+		"content":        text,
+		"excerpt":        text,
+		"slug":           {Type: "string"},
+		"status":         {Type: "string", Enum: toInterfaces(postStatuses)},
+		"author":         {Type: "integer"},
+		"featured_media": {Type: "integer"},
+		"comment_status": {Type: "string", Enum: []interface{}{"open", "closed"}},
+		"ping_status":    {Type: "string", Enum: []interface{}{"open", "closed"}},
+		"template":       {Type: "string"},
+		"meta":           {Type: "object", Properties: f.meta[restBase]},
+	}
+	if t.Hierarchical {
+		properties["parent"] = wp.SchemaProperty{Type: "integer"}
+		properties["menu_order"] = wp.SchemaProperty{Type: "integer"}
+	}
+	if t.HasTaxonomy("category") {
+		properties["categories"] = wp.SchemaProperty{Type: "array", Items: &wp.SchemaProperty{Type: "integer"}}
+	}
+	if t.HasTaxonomy("post_tag") {
+		properties["tags"] = wp.SchemaProperty{Type: "array", Items: &wp.SchemaProperty{Type: "integer"}}
+	}
+	if t.Slug == "post" {
+		properties["sticky"] = wp.SchemaProperty{Type: "boolean"}
+		properties["format"] = wp.SchemaProperty{Type: "string"}
+	}
+	for name, prop := range f.fields[restBase] {
+		properties[name] = prop
+	}
+	return properties
+}
+
+// authenticate は Username が設定されている場合に Basic 認証を確認します
+func (f *Fake) authenticate(r *http.Request) *wp.APIError {
+	if f.Username == "" {
+		return nil
+	}
+	auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Basic ")
+	if !ok {
+		return apiError(http.StatusUnauthorized, "rest_not_logged_in", "You are not currently logged in.")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(auth)
+	if err != nil {
+		return apiError(http.StatusUnauthorized, "rest_not_logged_in", "You are not currently logged in.")
+	}
+	user, pass, _ := strings.Cut(string(decoded), ":")
+	if user != f.Username {
+		return apiError(http.StatusUnauthorized, "invalid_username", "Unknown username. Check again or try your email address.")
+	}
+	if strings.ReplaceAll(pass, " ", "") != strings.ReplaceAll(f.Password, " ", "") {
+		return apiError(http.StatusUnauthorized, "incorrect_password", "The provided password is an invalid application password.")
+	}
+	return nil
+}
+
+// paginate は per_page（既定 10、最大 100）と page で一覧を区切り、X-WP-Total と X-WP-TotalPages を設定します
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) ([]T, *wp.APIError) {
+	perPage, page := 10, 1
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > 100 {
+			return nil, apiError(http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): per_page")
+		}
+		perPage = n
+	}
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return nil, apiError(http.StatusBadRequest, "rest_invalid_param", "Invalid parameter(s): page")
+		}
+		page = n
+	}
+
+	total := len(items)
+	totalPages := (total + perPage - 1) / perPage
+	if page > totalPages && total > 0 {
+		return nil, apiError(http.StatusBadRequest, "rest_post_invalid_page_number", "The page number requested is larger than the number of pages available.")
+	}
+	w.Header().Set("X-WP-Total", strconv.Itoa(total))
+	w.Header().Set("X-WP-TotalPages", strconv.Itoa(totalPages))
+
+	start := min((page-1)*perPage, total)
+	end := min(start+perPage, total)
+	return append([]T{}, items[start:end]...), nil
+}
+
+func readBody(r *http.Request) ([]byte, *wp.APIError) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, apiError(http.StatusBadRequest, "rest_invalid_json", "Invalid JSON body passed.")
+	}
+	if len(data) == 0 {
+		data = []byte("{}")
+	}
+	return data, nil
+}
+
+func mediaJSON(item *mediaItem) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         item.ID,
+		"source_url": item.URL,
+		"media_type": "image",
+		"mime_type":  item.mimeType,
+		"title":      map[string]string{"raw": item.name, "rendered": item.name},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError は WordPress と同じ {"code", "message", "data": {"status"}} の形式でエラーを返します。
+// term_exists の場合は data に既存のタームの term_id を含めます。
+func writeError(w http.ResponseWriter, apiErr *wp.APIError) {
+	data := map[string]interface{}{"status": apiErr.StatusCode}
+	if apiErr.TermID != 0 {
+		data["term_id"] = apiErr.TermID
+	}
+	writeJSON(w, apiErr.StatusCode, map[string]interface{}{
+		"code":    apiErr.Code,
+		"message": apiErr.Message,
+		"data":    data,
+	})
+}
+
+func toInterfaces(values []string) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}